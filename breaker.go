@@ -0,0 +1,290 @@
+package errors
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// BreakerState is the state of a CircuitBreaker.
+type BreakerState int
+
+const (
+	// BreakerClosed lets every call through.
+	BreakerClosed BreakerState = iota
+
+	// BreakerOpen fails every call fast until the
+	// cool-down elapses.
+	BreakerOpen
+
+	// BreakerHalfOpen lets a limited number of probe
+	// calls through to decide whether to close again.
+	BreakerHalfOpen
+)
+
+// String returns the name of the state.
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	}
+
+	return fmt.Sprintf("BreakerState(%d)", int(s))
+}
+
+// BreakerConfig configures a CircuitBreaker. Zero values
+// use the documented defaults.
+type BreakerConfig struct {
+	// Name identifies the protected dependency in the
+	// errors returned by the breaker.
+	Name string
+
+	// FailureThreshold is the number of consecutive failures
+	// that opens the breaker. Defaults to 5.
+	FailureThreshold int
+
+	// CoolDown is the time the breaker stays open before
+	// letting probe calls through. Defaults to 30 seconds.
+	CoolDown time.Duration
+
+	// HalfOpenMaxCalls is the maximum number of concurrent
+	// probe calls while half-open. Defaults to 1.
+	HalfOpenMaxCalls int
+
+	// SuccessThreshold is the number of successful probe
+	// calls that closes the breaker. Defaults to 1.
+	SuccessThreshold int
+
+	// IsFailure classifies the errors returned by the
+	// protected calls. Defaults to BreakerFailure.
+	IsFailure func(error) bool
+
+	// OnStateChange is called on every state transition.
+	// It is called synchronously, outside of the breaker lock.
+	OnStateChange func(name string, from, to BreakerState)
+}
+
+// CircuitBreaker stops calling a failing dependency and fails
+// fast with a ServiceUnavailable HTTPError until it recovers.
+// It is safe for concurrent use.
+type CircuitBreaker struct {
+	cfg BreakerConfig
+	now func() time.Time
+
+	mu            sync.Mutex
+	state         BreakerState
+	failures      int
+	successes     int
+	halfOpenCalls int
+	openedAt      time.Time
+
+	// generation is incremented on every state transition,
+	// the results of the calls acquired in an older one are
+	// ignored
+	generation uint64
+}
+
+// NewCircuitBreaker creates a new closed CircuitBreaker.
+func NewCircuitBreaker(cfg BreakerConfig) *CircuitBreaker {
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = 5
+	}
+	if cfg.CoolDown <= 0 {
+		cfg.CoolDown = 30 * time.Second
+	}
+	if cfg.HalfOpenMaxCalls <= 0 {
+		cfg.HalfOpenMaxCalls = 1
+	}
+	if cfg.SuccessThreshold <= 0 {
+		cfg.SuccessThreshold = 1
+	}
+	if cfg.IsFailure == nil {
+		cfg.IsFailure = BreakerFailure
+	}
+
+	return &CircuitBreaker{cfg: cfg, now: time.Now}
+}
+
+// BreakerFailure is the default failure classification of a
+// CircuitBreaker. Client errors (4xx HTTP errors other than
+// 408 and 429) and context cancellations do not count as
+// failures since they say nothing about the dependency health,
+// any other error does.
+func BreakerFailure(err error) bool {
+	if err == nil || stderrors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var httpErr *HTTPError
+	if stderrors.As(err, &httpErr) {
+		switch {
+		case httpErr.StatusCode == http.StatusRequestTimeout,
+			httpErr.StatusCode == http.StatusTooManyRequests:
+			return true
+		case httpErr.StatusCode < 500:
+			return false
+		}
+	}
+
+	return true
+}
+
+// State returns the current state of the breaker.
+func (b *CircuitBreaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerOpen && !b.now().Before(b.openedAt.Add(b.cfg.CoolDown)) {
+		return BreakerHalfOpen
+	}
+
+	return b.state
+}
+
+// Do calls fn if the breaker allows it and records the result.
+//
+// When the breaker is open, fn is not called and a 503
+// HTTPError is returned with a Retry-After header set to the
+// remaining cool-down and an ErrorInfo detail with the
+// "CIRCUIT_OPEN" reason. If fn panics, the call is recorded
+// as a failure and the panic is propagated.
+func (b *CircuitBreaker) Do(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	generation, openErr := b.acquire()
+	if openErr != nil {
+		return openErr
+	}
+
+	failed := true
+	defer func() {
+		b.release(generation, failed)
+	}()
+
+	err = fn(ctx)
+	failed = b.cfg.IsFailure(err)
+
+	return err
+}
+
+// acquire reserves a call, it returns the generation of the
+// call or the error to fail fast with when the call is not
+// allowed.
+func (b *CircuitBreaker) acquire() (uint64, error) {
+	b.mu.Lock()
+
+	var transitions [][2]BreakerState
+	if b.state == BreakerOpen && !b.now().Before(b.openedAt.Add(b.cfg.CoolDown)) {
+		transitions = append(transitions, b.setState(BreakerHalfOpen))
+	}
+
+	var openErr error
+	switch b.state {
+	case BreakerOpen:
+		openErr = b.openError(b.openedAt.Add(b.cfg.CoolDown).Sub(b.now()))
+	case BreakerHalfOpen:
+		if b.halfOpenCalls >= b.cfg.HalfOpenMaxCalls {
+			openErr = b.openError(time.Second)
+		} else {
+			b.halfOpenCalls++
+		}
+	}
+	generation := b.generation
+	b.mu.Unlock()
+
+	b.notify(transitions)
+	return generation, openErr
+}
+
+// release records the result of a call reserved by acquire,
+// unless the breaker changed state since.
+func (b *CircuitBreaker) release(generation uint64, failed bool) {
+	b.mu.Lock()
+	if generation != b.generation {
+		b.mu.Unlock()
+		return
+	}
+
+	var transitions [][2]BreakerState
+	switch b.state {
+	case BreakerClosed:
+		if !failed {
+			b.failures = 0
+			break
+		}
+
+		b.failures++
+		if b.failures >= b.cfg.FailureThreshold {
+			transitions = append(transitions, b.setState(BreakerOpen))
+		}
+	case BreakerHalfOpen:
+		b.halfOpenCalls--
+		if failed {
+			transitions = append(transitions, b.setState(BreakerOpen))
+			break
+		}
+
+		b.successes++
+		if b.successes >= b.cfg.SuccessThreshold {
+			transitions = append(transitions, b.setState(BreakerClosed))
+		}
+	}
+	b.mu.Unlock()
+
+	b.notify(transitions)
+}
+
+// setState transitions the breaker to the given state and
+// resets its counters. It must be called with the lock held.
+func (b *CircuitBreaker) setState(to BreakerState) [2]BreakerState {
+	from := b.state
+	b.state = to
+	b.failures = 0
+	b.successes = 0
+	b.generation++
+
+	switch to {
+	case BreakerOpen:
+		b.openedAt = b.now()
+		b.halfOpenCalls = 0
+	case BreakerClosed:
+		b.halfOpenCalls = 0
+	}
+
+	return [2]BreakerState{from, to}
+}
+
+func (b *CircuitBreaker) notify(transitions [][2]BreakerState) {
+	if b.cfg.OnStateChange == nil {
+		return
+	}
+
+	for _, t := range transitions {
+		b.cfg.OnStateChange(b.cfg.Name, t[0], t[1])
+	}
+}
+
+func (b *CircuitBreaker) openError(remaining time.Duration) error {
+	if remaining < time.Second {
+		remaining = time.Second
+	}
+
+	return NewServiceUnavailableError(
+		fmt.Sprintf("circuit breaker %q is open", b.cfg.Name),
+		nil,
+	).(*HTTPError).
+		WithRetryAfter(remaining).
+		WithDetails(ErrorInfo{Reason: "CIRCUIT_OPEN", Domain: b.cfg.Name})
+}
+
+// IsCircuitOpen reports whether the given error was returned
+// by an open CircuitBreaker.
+func IsCircuitOpen(err error) bool {
+	info, ok := FindDetail[ErrorInfo](err)
+	return ok && info.Reason == "CIRCUIT_OPEN"
+}
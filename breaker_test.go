@@ -0,0 +1,249 @@
+package errors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a manually advanced clock.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func newTestBreaker(cfg BreakerConfig) (*CircuitBreaker, *fakeClock, *[]string) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	transitions := &[]string{}
+	cfg.OnStateChange = func(name string, from, to BreakerState) {
+		*transitions = append(*transitions, fmt.Sprintf("%s: %s -> %s", name, from, to))
+	}
+
+	b := NewCircuitBreaker(cfg)
+	b.now = clock.Now
+	return b, clock, transitions
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	t.Parallel()
+
+	fail := func(ctx context.Context) error { return NewBadGatewayError("upstream down", nil) }
+	succeed := func(ctx context.Context) error { return nil }
+
+	t.Run("should open after consecutive failures and fail fast", func(t *testing.T) {
+		b, clock, transitions := newTestBreaker(BreakerConfig{Name: "users", FailureThreshold: 2, CoolDown: 10 * time.Second})
+
+		_ = b.Do(context.Background(), fail)
+		_ = b.Do(context.Background(), fail)
+		if b.State() != BreakerOpen {
+			t.Fatalf("\n got:  %v\n want: %v", b.State(), BreakerOpen)
+		}
+
+		clock.Advance(3 * time.Second)
+		called := false
+		err := b.Do(context.Background(), func(ctx context.Context) error {
+			called = true
+			return nil
+		})
+
+		if called {
+			t.Fatalf("expected fn not to be called while open")
+		}
+
+		httpErr, ok := err.(*HTTPError)
+		if !ok || httpErr.StatusCode != http.StatusServiceUnavailable || !IsCircuitOpen(err) {
+			t.Fatalf("expected a circuit open 503 error, got %v", err)
+		}
+
+		if d, ok := httpErr.RetryAfter(); !ok || d != 7*time.Second {
+			t.Fatalf("\n got:  %v\n want: %v", d, 7*time.Second)
+		}
+
+		if len(*transitions) != 1 || (*transitions)[0] != "users: closed -> open" {
+			t.Fatalf("unexpected transitions: %v", *transitions)
+		}
+	})
+
+	t.Run("should close after a successful probe", func(t *testing.T) {
+		b, clock, transitions := newTestBreaker(BreakerConfig{Name: "users", FailureThreshold: 1, CoolDown: time.Second})
+
+		_ = b.Do(context.Background(), fail)
+		clock.Advance(time.Second)
+
+		if b.State() != BreakerHalfOpen {
+			t.Fatalf("\n got:  %v\n want: %v", b.State(), BreakerHalfOpen)
+		}
+
+		if err := b.Do(context.Background(), succeed); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		want := []string{"users: closed -> open", "users: open -> half-open", "users: half-open -> closed"}
+		if fmt.Sprint(*transitions) != fmt.Sprint(want) {
+			t.Fatalf("\n got:  %v\n want: %v", *transitions, want)
+		}
+	})
+
+	t.Run("should open again when a probe fails", func(t *testing.T) {
+		b, clock, _ := newTestBreaker(BreakerConfig{FailureThreshold: 1, CoolDown: time.Second})
+
+		_ = b.Do(context.Background(), fail)
+		clock.Advance(time.Second)
+		_ = b.Do(context.Background(), fail)
+
+		if b.State() != BreakerOpen {
+			t.Fatalf("\n got:  %v\n want: %v", b.State(), BreakerOpen)
+		}
+	})
+
+	t.Run("should limit concurrent probes", func(t *testing.T) {
+		b, clock, _ := newTestBreaker(BreakerConfig{FailureThreshold: 1, CoolDown: time.Second})
+
+		_ = b.Do(context.Background(), fail)
+		clock.Advance(time.Second)
+
+		probing := make(chan struct{})
+		done := make(chan struct{})
+		go func() {
+			_ = b.Do(context.Background(), func(ctx context.Context) error {
+				close(probing)
+				<-done
+				return nil
+			})
+		}()
+		<-probing
+
+		if err := b.Do(context.Background(), succeed); !IsCircuitOpen(err) {
+			t.Fatalf("expected the second probe to be rejected, got %v", err)
+		}
+		close(done)
+	})
+
+	t.Run("should ignore the results of the calls from a previous state", func(t *testing.T) {
+		b, clock, _ := newTestBreaker(BreakerConfig{FailureThreshold: 1, CoolDown: time.Second})
+
+		started := make(chan struct{})
+		done := make(chan struct{})
+		finished := make(chan struct{})
+		go func() {
+			defer close(finished)
+			_ = b.Do(context.Background(), func(ctx context.Context) error {
+				close(started)
+				<-done
+				return nil
+			})
+		}()
+		<-started
+
+		_ = b.Do(context.Background(), fail)
+		clock.Advance(time.Second)
+
+		probing := make(chan struct{})
+		release := make(chan struct{})
+		go func() {
+			_ = b.Do(context.Background(), func(ctx context.Context) error {
+				close(probing)
+				<-release
+				return nil
+			})
+		}()
+		<-probing
+
+		// the stale success of the call acquired while closed
+		close(done)
+		<-finished
+
+		if b.State() != BreakerHalfOpen {
+			t.Fatalf("\n got:  %v\n want: %v", b.State(), BreakerHalfOpen)
+		}
+		if err := b.Do(context.Background(), succeed); !IsCircuitOpen(err) {
+			t.Fatalf("expected the probes to still be limited, got %v", err)
+		}
+		close(release)
+	})
+
+	t.Run("should not count client errors as failures", func(t *testing.T) {
+		b, _, _ := newTestBreaker(BreakerConfig{FailureThreshold: 1})
+
+		err := b.Do(context.Background(), func(ctx context.Context) error {
+			return NewNotFoundError("not found", nil)
+		})
+
+		if b.State() != BreakerClosed || IsCircuitOpen(err) {
+			t.Fatalf("expected breaker to stay closed")
+		}
+	})
+
+	t.Run("should count panics as failures", func(t *testing.T) {
+		b, _, _ := newTestBreaker(BreakerConfig{FailureThreshold: 1})
+
+		func() {
+			defer func() { _ = recover() }()
+			_ = b.Do(context.Background(), func(ctx context.Context) error { panic("boom") })
+		}()
+
+		if b.State() != BreakerOpen {
+			t.Fatalf("\n got:  %v\n want: %v", b.State(), BreakerOpen)
+		}
+	})
+
+	t.Run("should be safe for concurrent use", func(t *testing.T) {
+		b := NewCircuitBreaker(BreakerConfig{FailureThreshold: 3, CoolDown: time.Millisecond})
+
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				for j := 0; j < 50; j++ {
+					_ = b.Do(context.Background(), func(ctx context.Context) error {
+						if (i+j)%2 == 0 {
+							return errors.New("boom")
+						}
+						return nil
+					})
+				}
+			}(i)
+		}
+		wg.Wait()
+	})
+}
+
+func TestBreakerFailure(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{context.Canceled, false},
+		{NewBadRequestError("message", nil), false},
+		{NewTooManyRequestsError("message", nil), true},
+		{NewRequestTimeoutError("message", nil), true},
+		{NewInternalServerError("message", nil), true},
+		{New("message"), true},
+	}
+
+	for _, tt := range tests {
+		if got := BreakerFailure(tt.err); got != tt.want {
+			t.Errorf("BreakerFailure(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package errors
+
+import (
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// maxResponseBody is the maximum number of bytes of a
+// response body read by FromResponse.
+const maxResponseBody = 1 << 20
+
+// errorHeaders are the response headers kept by FromResponse.
+var errorHeaders = []string{
+	"Retry-After",
+	"WWW-Authenticate",
+	"Allow",
+}
+
+// FromResponse decodes the error of an HTTP response into
+// an HTTPError. It does not close the response body.
+//
+// JSON bodies written by WriteError (including problem+json)
+// are decoded back to their name, message, cause and details.
+// Other bodies are kept as the message. The Retry-After,
+// WWW-Authenticate and Allow response headers are set to the
+// Header property of the returned error.
+func FromResponse(resp *http.Response) (*HTTPError, error) {
+	b, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	if err != nil {
+		return nil, NewWithNameAndErr("error", "unable to read response body", err)
+	}
+
+	httpErr := decodeResponseBody(resp.Header.Get("Content-Type"), b)
+	httpErr.StatusCode = resp.StatusCode
+	if httpErr.Name == "" {
+		httpErr.Name = statusName(resp.StatusCode)
+	}
+	if httpErr.Message == "" {
+		httpErr.Message = http.StatusText(resp.StatusCode)
+	}
+
+	for _, k := range errorHeaders {
+		for _, v := range resp.Header.Values(k) {
+			httpErr.WithHeader(k, v)
+		}
+	}
+
+	return httpErr, nil
+}
+
+func decodeResponseBody(contentType string, b []byte) *HTTPError {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	if mediaType == MediaTypeProblemJSON {
+		var p problem
+		if err := json.Unmarshal(b, &p); err == nil {
+			return &HTTPError{Name: p.Name, Message: p.Detail, Details: p.Details}
+		}
+	}
+
+	if e, err := FromJSON(b); err == nil {
+		switch e := e.(type) {
+		case *HTTPError:
+			return e
+		case *GenericError:
+			return &HTTPError{Name: e.Name, Message: e.Message, Err: e.Original, Details: e.Details}
+		}
+	}
+
+	if mediaType == MediaTypeText {
+		return &HTTPError{Message: strings.TrimSpace(string(b))}
+	}
+
+	return &HTTPError{}
+}
@@ -0,0 +1,79 @@
+package errors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestFromResponse(t *testing.T) {
+	t.Parallel()
+
+	write := func(accept string, err error) *http.Response {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Accept", accept)
+		rec := httptest.NewRecorder()
+		WriteError(rec, r, err)
+		return rec.Result()
+	}
+
+	t.Run("should decode a JSON error with its headers", func(t *testing.T) {
+		want := NewTooManyRequestsError("slow down", New("quota")).(*HTTPError).
+			WithDetails(ErrorInfo{Reason: "QUOTA"}).
+			WithRetryAfter(3 * time.Second)
+
+		got, err := FromResponse(write(MediaTypeJSON, want))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if string(got.JSON()) != string(want.JSON()) {
+			t.Fatalf("\n got:  %v\n want: %v", string(got.JSON()), string(want.JSON()))
+		}
+
+		if d, ok := got.RetryAfter(); !ok || d != 3*time.Second {
+			t.Fatalf("\n got:  %v\n want: %v", d, 3*time.Second)
+		}
+
+		if got.Header.Get("Content-Type") != "" {
+			t.Fatalf("expected only error headers to be kept, got %v", got.Header)
+		}
+	})
+
+	t.Run("should decode a problem", func(t *testing.T) {
+		got, err := FromResponse(write(MediaTypeProblemJSON, NewNotFoundError("user not found", nil)))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if got.StatusCode != http.StatusNotFound || got.Name != "not_found_error" || got.Message != "user not found" {
+			t.Fatalf("unexpected error: %s", got.JSON())
+		}
+	})
+
+	t.Run("should keep other bodies as the message", func(t *testing.T) {
+		got, err := FromResponse(write(MediaTypeText, NewBadGatewayError("upstream", nil)))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if got.Name != "bad_gateway_error" || got.Message != "502 bad_gateway_error: upstream" {
+			t.Fatalf("unexpected error: %s", got.JSON())
+		}
+	})
+
+	t.Run("should use the status text when the body is empty", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		rec.WriteHeader(http.StatusGatewayTimeout)
+
+		got, err := FromResponse(rec.Result())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if got.Name != "gateway_timeout_error" || got.Message != "Gateway Timeout" {
+			t.Fatalf("unexpected error: %s", got.JSON())
+		}
+	})
+}
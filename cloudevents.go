@@ -0,0 +1,250 @@
+package errors
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// CloudEventTypePrefix is the prefix of the type of the
+// CloudEvents, followed by the name of the error.
+const CloudEventTypePrefix = "com.github.iolave.go-errors."
+
+// CloudEvent is the CloudEvents 1.0 JSON envelope of an error,
+// the data being the JSON representation of the error.
+//
+// Extensions are the extension context attributes of the
+// event, serialized alongside the other attributes.
+//
+// See https://github.com/cloudevents/spec/blob/v1.0.2/cloudevents/spec.md
+type CloudEvent struct {
+	SpecVersion     string
+	ID              string
+	Source          string
+	Type            string
+	Subject         string
+	Time            time.Time
+	DataContentType string
+	Data            json.RawMessage
+	Extensions      map[string]string
+}
+
+// cloudEventAttributes are the context attributes defined
+// by the specification.
+var cloudEventAttributes = map[string]bool{
+	"specversion":     true,
+	"id":              true,
+	"source":          true,
+	"type":            true,
+	"subject":         true,
+	"time":            true,
+	"datacontenttype": true,
+	"dataschema":      true,
+	"data":            true,
+	"data_base64":     true,
+}
+
+// NewCloudEvent wraps an error into a CloudEvent from the
+// given source, with a random ID and the current time.
+func NewCloudEvent(source string, err error) CloudEvent {
+	return NewCloudEventFromEvent(source, NewEvent(context.Background(), err))
+}
+
+// NewCloudEventFromEvent wraps the error of an Event into a
+// CloudEvent from the given source, with a random ID and the
+// time of the event. The fields of the event are set as
+// extensions, their names lowercased and stripped from the
+// characters other than letters and digits.
+//
+// A nil error is wrapped as an internal server error, as
+// WriteError does.
+func NewCloudEventFromEvent(source string, e Event) CloudEvent {
+	if e.Err == nil {
+		e.Err = toHTTPError(nil)
+	}
+
+	name := toHTTPError(e.Err).Name
+	data := e.Err.JSON()
+
+	ce := CloudEvent{
+		SpecVersion:     "1.0",
+		ID:              newUUID(),
+		Source:          source,
+		Type:            CloudEventTypePrefix + name,
+		Time:            e.Time,
+		DataContentType: MediaTypeJSON,
+		Data:            data,
+	}
+
+	for k, v := range e.Fields {
+		k = extensionName(k)
+		if k == "" || cloudEventAttributes[k] {
+			continue
+		}
+
+		if ce.Extensions == nil {
+			ce.Extensions = map[string]string{}
+		}
+		ce.Extensions[k] = v
+	}
+
+	return ce
+}
+
+// extensionName converts a field name to a valid extension
+// attribute name.
+func extensionName(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + 'a' - 'A'
+		default:
+			return -1
+		}
+	}, s)
+}
+
+// newUUID returns a random version 4 UUID.
+func newUUID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+
+	h := hex.EncodeToString(b[:])
+	return h[:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:]
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (c CloudEvent) MarshalJSON() ([]byte, error) {
+	m := make(map[string]any, len(c.Extensions)+8)
+	for k, v := range c.Extensions {
+		m[k] = v
+	}
+
+	m["specversion"] = c.SpecVersion
+	m["id"] = c.ID
+	m["source"] = c.Source
+	m["type"] = c.Type
+	if c.Subject != "" {
+		m["subject"] = c.Subject
+	}
+	if !c.Time.IsZero() {
+		m["time"] = c.Time.Format(time.RFC3339Nano)
+	}
+	if c.DataContentType != "" {
+		m["datacontenttype"] = c.DataContentType
+	}
+	if c.Data != nil {
+		m["data"] = c.Data
+	}
+
+	return json.Marshal(m)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+// Extension attributes that are not strings are kept as
+// their JSON representation.
+func (c *CloudEvent) UnmarshalJSON(b []byte) error {
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(b, &m); err != nil {
+		return err
+	}
+
+	*c = CloudEvent{Data: m["data"]}
+	attrs := map[string]*string{
+		"specversion":     &c.SpecVersion,
+		"id":              &c.ID,
+		"source":          &c.Source,
+		"type":            &c.Type,
+		"subject":         &c.Subject,
+		"datacontenttype": &c.DataContentType,
+	}
+
+	for k, raw := range m {
+		if dst, ok := attrs[k]; ok {
+			if err := json.Unmarshal(raw, dst); err != nil {
+				return NewWithNameAndErr("error", "invalid cloud event attribute "+k, err)
+			}
+			continue
+		}
+
+		switch {
+		case k == "time":
+			if err := json.Unmarshal(raw, &c.Time); err != nil {
+				return NewWithNameAndErr("error", "invalid cloud event attribute time", err)
+			}
+		case !cloudEventAttributes[k]:
+			if c.Extensions == nil {
+				c.Extensions = map[string]string{}
+			}
+
+			var s string
+			if err := json.Unmarshal(raw, &s); err != nil {
+				s = string(raw)
+			}
+			c.Extensions[k] = s
+		}
+	}
+
+	return nil
+}
+
+// DecodeError decodes the error of the data of the event.
+func (c CloudEvent) DecodeError() (Error, error) {
+	if len(c.Data) == 0 {
+		return nil, New("invalid cloud event: missing data")
+	}
+
+	return FromJSON(c.Data)
+}
+
+// ParseCloudEvent decodes a CloudEvents 1.0 JSON envelope and
+// returns the error of it's data.
+func ParseCloudEvent(b []byte) (Error, error) {
+	var c CloudEvent
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, NewWithNameAndErr("error", "invalid cloud event", err)
+	}
+
+	switch {
+	case c.SpecVersion != "1.0":
+		return nil, New("invalid cloud event: unsupported specversion " + c.SpecVersion)
+	case c.ID == "":
+		return nil, New("invalid cloud event: missing id")
+	case c.Source == "":
+		return nil, New("invalid cloud event: missing source")
+	case c.Type == "":
+		return nil, New("invalid cloud event: missing type")
+	}
+
+	return c.DecodeError()
+}
+
+// CloudEventSink converts the events to CloudEvents and
+// publishes them with a function, e.g. to an event bus.
+type CloudEventSink struct {
+	source  string
+	publish func(ctx context.Context, events []CloudEvent) error
+}
+
+// NewCloudEventSink creates a new CloudEventSink converting
+// the events from the given source.
+func NewCloudEventSink(source string, publish func(ctx context.Context, events []CloudEvent) error) *CloudEventSink {
+	return &CloudEventSink{source: source, publish: publish}
+}
+
+// Write converts the events and publishes them.
+func (s *CloudEventSink) Write(ctx context.Context, events []Event) error {
+	ces := make([]CloudEvent, 0, len(events))
+	for _, e := range events {
+		ces = append(ces, NewCloudEventFromEvent(s.source, e))
+	}
+
+	return s.publish(ctx, ces)
+}
@@ -0,0 +1,190 @@
+package errors
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestNewCloudEvent(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should wrap the error into a CloudEvent", func(t *testing.T) {
+		ce := NewCloudEvent("/users", NewNotFoundError("user not found", nil))
+
+		if !uuidPattern.MatchString(ce.ID) {
+			t.Fatalf("unexpected id %s", ce.ID)
+		}
+		if time.Since(ce.Time) > time.Minute {
+			t.Fatalf("unexpected time %v", ce.Time)
+		}
+
+		ce.ID, ce.Time = "id", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+		b, err := json.Marshal(ce)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		want := `{"data":{"statusCode":404,"name":"not_found_error","message":"user not found","error":null},` +
+			`"datacontenttype":"application/json","id":"id","source":"/users","specversion":"1.0",` +
+			`"time":"2024-01-02T03:04:05Z","type":"com.github.iolave.go-errors.not_found_error"}`
+		if got := string(b); got != want {
+			t.Fatalf("\n got:  %v\n want: %v", got, want)
+		}
+	})
+
+	t.Run("should set the fields of an event as extensions", func(t *testing.T) {
+		e := NewEvent(ContextWithFields(context.Background(), map[string]string{
+			"requestId": "abc",
+			"status":    "500",
+			"id":        "ignored",
+			"-":         "ignored",
+		}), New("boom"))
+
+		ce := NewCloudEventFromEvent("/users", e)
+		if ce.Type != "com.github.iolave.go-errors.error" || !ce.Time.Equal(e.Time) {
+			t.Fatalf("unexpected event: %+v", ce)
+		}
+
+		want := map[string]string{"requestid": "abc", "status": "500"}
+		if !reflect.DeepEqual(ce.Extensions, want) {
+			t.Fatalf("\n got:  %v\n want: %v", ce.Extensions, want)
+		}
+
+		b, err := json.Marshal(ce)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !strings.Contains(string(b), `"requestid":"abc"`) {
+			t.Fatalf("expected the extensions to be serialized, got %s", b)
+		}
+	})
+
+	t.Run("should wrap a nil error as an internal server error", func(t *testing.T) {
+		ce := NewCloudEvent("/users", nil)
+		if ce.Type != "com.github.iolave.go-errors.internal_server_error" {
+			t.Fatalf("unexpected type %s", ce.Type)
+		}
+
+		b, _ := json.Marshal(ce)
+		got, err := ParseCloudEvent(b)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if httpErr, ok := got.(*HTTPError); !ok || httpErr.StatusCode != 500 {
+			t.Fatalf("unexpected error %v", got)
+		}
+	})
+
+	t.Run("should generate unique ids", func(t *testing.T) {
+		if a, b := NewCloudEvent("/", New("boom")), NewCloudEvent("/", New("boom")); a.ID == b.ID {
+			t.Fatalf("expected unique ids, got %s twice", a.ID)
+		}
+	})
+}
+
+func TestParseCloudEvent(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should extract the error of a CloudEvent", func(t *testing.T) {
+		b, err := json.Marshal(NewCloudEvent("/users", NewNotFoundError("user not found", New("missing row"))))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		got, err := ParseCloudEvent(b)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if want := "not_found_error: user not found (error: missing row)"; got.Error() != want {
+			t.Fatalf("\n got:  %v\n want: %v", got, want)
+		}
+		if _, ok := got.(*HTTPError); !ok {
+			t.Fatalf("expected an *HTTPError, got %T", got)
+		}
+	})
+
+	t.Run("should keep the extensions", func(t *testing.T) {
+		b := []byte(`{"specversion":"1.0","id":"1","source":"/","type":"t","time":"2024-01-02T03:04:05Z",` +
+			`"requestid":"abc","attempt":2,"data":{"name":"error","message":"boom"}}`)
+
+		var ce CloudEvent
+		if err := json.Unmarshal(b, &ce); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		want := map[string]string{"requestid": "abc", "attempt": "2"}
+		if !reflect.DeepEqual(ce.Extensions, want) {
+			t.Fatalf("\n got:  %v\n want: %v", ce.Extensions, want)
+		}
+		if !ce.Time.Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)) {
+			t.Fatalf("unexpected time %v", ce.Time)
+		}
+
+		err, decodeErr := ce.DecodeError()
+		if decodeErr != nil {
+			t.Fatalf("unexpected error: %v", decodeErr)
+		}
+		if want := "error: boom"; err.Error() != want {
+			t.Fatalf("\n got:  %v\n want: %v", err, want)
+		}
+	})
+
+	t.Run("should reject invalid envelopes", func(t *testing.T) {
+		tests := map[string]string{
+			`{"specversion":"0.3","id":"1","source":"/","type":"t","data":{}}`: "invalid cloud event: unsupported specversion 0.3",
+			`{"specversion":"1.0","source":"/","type":"t","data":{}}`:          "invalid cloud event: missing id",
+			`{"specversion":"1.0","id":"1","type":"t","data":{}}`:              "invalid cloud event: missing source",
+			`{"specversion":"1.0","id":"1","source":"/","data":{}}`:            "invalid cloud event: missing type",
+			`{"specversion":"1.0","id":"1","source":"/","type":"t"}`:           "invalid cloud event: missing data",
+			`{"specversion":1}`: "invalid cloud event attribute specversion",
+		}
+
+		for in, want := range tests {
+			_, err := ParseCloudEvent([]byte(in))
+			if err == nil || !strings.Contains(err.Error(), want) {
+				t.Fatalf("%s:\n got:  %v\n want: %v", in, err, want)
+			}
+		}
+	})
+}
+
+func TestCloudEventSink(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should publish the events as CloudEvents", func(t *testing.T) {
+		var published []CloudEvent
+		sink := NewCloudEventSink("/users", func(ctx context.Context, events []CloudEvent) error {
+			published = append(published, events...)
+			return nil
+		})
+
+		d := NewDispatcher(sink, DispatcherConfig{})
+		d.Report(context.Background(), NewBadGatewayError("upstream failed", nil))
+		if err := d.Close(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(published) != 1 || published[0].Type != "com.github.iolave.go-errors.bad_gateway_error" ||
+			published[0].Source != "/users" {
+			t.Fatalf("unexpected events: %+v", published)
+		}
+	})
+
+	t.Run("should return the publish errors", func(t *testing.T) {
+		sink := NewCloudEventSink("/", func(ctx context.Context, events []CloudEvent) error {
+			return New("bus unavailable")
+		})
+
+		if err := sink.Write(context.Background(), testEvents(1)); err == nil || err.Error() != "error: bus unavailable" {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
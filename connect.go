@@ -0,0 +1,167 @@
+package errors
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// ConnectError is the JSON representation of an error
+// returned by a Connect unary endpoint.
+//
+// See https://connectrpc.com/docs/protocol#error-end-stream
+type ConnectError struct {
+	Code    string          `json:"code"`
+	Message string          `json:"message,omitempty"`
+	Details []ConnectDetail `json:"details,omitempty"`
+}
+
+// ConnectDetail is an error detail of a ConnectError.
+//
+// The value is a base64 encoded protobuf message, it is
+// not decoded by this package. When the optional debug
+// property is present, it is decoded as the registered
+// Detail of the same type.
+type ConnectDetail struct {
+	Type  string          `json:"type"`
+	Value string          `json:"value"`
+	Debug json.RawMessage `json:"debug,omitempty"`
+}
+
+// connectStatusCodes maps Connect error codes to
+// the HTTP status codes specified by Connect.
+var connectStatusCodes = map[string]int{
+	"canceled":            499,
+	"unknown":             http.StatusInternalServerError,
+	"invalid_argument":    http.StatusBadRequest,
+	"deadline_exceeded":   http.StatusGatewayTimeout,
+	"not_found":           http.StatusNotFound,
+	"already_exists":      http.StatusConflict,
+	"permission_denied":   http.StatusForbidden,
+	"resource_exhausted":  http.StatusTooManyRequests,
+	"failed_precondition": http.StatusBadRequest,
+	"aborted":             http.StatusConflict,
+	"out_of_range":        http.StatusBadRequest,
+	"unimplemented":       http.StatusNotImplemented,
+	"internal":            http.StatusInternalServerError,
+	"unavailable":         http.StatusServiceUnavailable,
+	"data_loss":           http.StatusInternalServerError,
+	"unauthenticated":     http.StatusUnauthorized,
+}
+
+// connectCodes maps HTTP status codes to the Connect
+// error code that best describes them.
+var connectCodes = map[int]string{
+	http.StatusBadRequest:          "invalid_argument",
+	http.StatusUnauthorized:        "unauthenticated",
+	http.StatusForbidden:           "permission_denied",
+	http.StatusNotFound:            "not_found",
+	http.StatusRequestTimeout:      "deadline_exceeded",
+	http.StatusConflict:            "already_exists",
+	http.StatusPreconditionFailed:  "failed_precondition",
+	http.StatusTooManyRequests:     "resource_exhausted",
+	499:                            "canceled",
+	http.StatusInternalServerError: "internal",
+	http.StatusNotImplemented:      "unimplemented",
+	http.StatusBadGateway:          "unavailable",
+	http.StatusServiceUnavailable:  "unavailable",
+	http.StatusGatewayTimeout:      "deadline_exceeded",
+}
+
+// ConnectStatusCode returns the HTTP status code of the given
+// Connect error code. Unknown codes are mapped to 500.
+func ConnectStatusCode(code string) int {
+	if statusCode, ok := connectStatusCodes[code]; ok {
+		return statusCode
+	}
+
+	return http.StatusInternalServerError
+}
+
+// ConnectCode returns the Connect error code of the given HTTP
+// status code. Status codes without a Connect equivalent are
+// mapped to "unknown".
+func ConnectCode(statusCode int) string {
+	if code, ok := connectCodes[statusCode]; ok {
+		return code
+	}
+
+	return "unknown"
+}
+
+// NewConnectError converts an error to a ConnectError.
+//
+// The code is derived from the HTTPError status code, errors
+// that are not an HTTPError are treated as internal errors.
+// Details are not included since Connect expects them to be
+// protobuf messages.
+func NewConnectError(err error) ConnectError {
+	httpErr := toHTTPError(err)
+
+	return ConnectError{
+		Code:    ConnectCode(httpErr.StatusCode),
+		Message: httpErr.Message,
+	}
+}
+
+// HTTPError converts the ConnectError to an HTTPError.
+//
+// The status code is derived from the Connect code and the
+// name is the one used by the constructor of that status code.
+// Details with a debug representation of a registered type are
+// kept as the HTTPError details.
+func (e ConnectError) HTTPError() *HTTPError {
+	statusCode := ConnectStatusCode(e.Code)
+	httpErr := &HTTPError{
+		StatusCode: statusCode,
+		Name:       statusName(statusCode),
+		Message:    e.Message,
+	}
+
+	for _, d := range e.Details {
+		if len(d.Debug) == 0 {
+			continue
+		}
+
+		detailsMu.RLock()
+		decode, ok := detailDecoders["type.googleapis.com/"+d.Type]
+		detailsMu.RUnlock()
+		if !ok {
+			continue
+		}
+
+		if detail, err := decode(d.Debug); err == nil {
+			httpErr.WithDetails(detail)
+		}
+	}
+
+	return httpErr
+}
+
+// WriteConnectError writes the given error to w as a Connect
+// JSON error with the status code specified for it's code.
+func WriteConnectError(w http.ResponseWriter, err error) {
+	connectErr := NewConnectError(err)
+	b, _ := json.Marshal(connectErr)
+
+	statusCode := ConnectStatusCode(connectErr.Code)
+	onWritten(w, nil, err, statusCode)
+	writeHeader(w, err)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_, _ = w.Write(b)
+}
+
+// ParseConnectError decodes a Connect JSON error and converts
+// it to an HTTPError.
+func ParseConnectError(b []byte) (*HTTPError, error) {
+	var connectErr ConnectError
+	if err := json.Unmarshal(b, &connectErr); err != nil {
+		return nil, NewWithNameAndErr("error", "invalid connect error", err)
+	}
+
+	if connectErr.Code == "" {
+		return nil, New("invalid connect error: missing code")
+	}
+
+	return connectErr.HTTPError(), nil
+}
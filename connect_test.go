@@ -0,0 +1,110 @@
+package errors
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestConnectStatusCode(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]int{
+		"canceled":            499,
+		"deadline_exceeded":   http.StatusGatewayTimeout,
+		"failed_precondition": http.StatusBadRequest,
+		"not_found":           http.StatusNotFound,
+		"unauthenticated":     http.StatusUnauthorized,
+		"data_loss":           http.StatusInternalServerError,
+		"something_else":      http.StatusInternalServerError,
+	}
+
+	for code, want := range tests {
+		if got := ConnectStatusCode(code); got != want {
+			t.Errorf("ConnectStatusCode(%q) = %d, want %d", code, got, want)
+		}
+	}
+}
+
+func TestConnectCode(t *testing.T) {
+	t.Parallel()
+
+	tests := map[int]string{
+		http.StatusBadRequest:         "invalid_argument",
+		http.StatusForbidden:          "permission_denied",
+		http.StatusTooManyRequests:    "resource_exhausted",
+		http.StatusGatewayTimeout:     "deadline_exceeded",
+		http.StatusServiceUnavailable: "unavailable",
+		http.StatusTeapot:             "unknown",
+	}
+
+	for statusCode, want := range tests {
+		if got := ConnectCode(statusCode); got != want {
+			t.Errorf("ConnectCode(%d) = %q, want %q", statusCode, got, want)
+		}
+	}
+}
+
+func TestParseConnectError(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should round trip an http error", func(t *testing.T) {
+		want := NewNotFoundError("user not found", nil).(*HTTPError)
+		b, _ := json.Marshal(NewConnectError(want))
+
+		got, err := ParseConnectError(b)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if string(got.JSON()) != string(want.JSON()) {
+			t.Fatalf("\n got:  %v\n want: %v", string(got.JSON()), string(want.JSON()))
+		}
+	})
+
+	t.Run("should decode details with a debug representation", func(t *testing.T) {
+		in := `{"code":"resource_exhausted","message":"slow down","details":[` +
+			`{"type":"google.rpc.RetryInfo","value":"","debug":{"retryDelay":"3s"}},` +
+			`{"type":"acme.Unknown","value":"","debug":{"foo":"bar"}}]}`
+
+		got, err := ParseConnectError([]byte(in))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if got.StatusCode != http.StatusTooManyRequests || len(got.Details) != 1 {
+			t.Fatalf("unexpected http error: %s", got.JSON())
+		}
+
+		if info, ok := FindDetail[RetryInfo](got); !ok || info.RetryDelay.Seconds() != 3 {
+			t.Fatalf("expected a RetryInfo detail, got %#v", got.Details)
+		}
+	})
+
+	t.Run("should fail on invalid errors", func(t *testing.T) {
+		for _, in := range []string{`nope`, `{"message":"missing code"}`} {
+			if _, err := ParseConnectError([]byte(in)); err == nil {
+				t.Fatalf("expected an error for %s", in)
+			}
+		}
+	})
+}
+
+func TestWriteConnectError(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should write the connect status code and body", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		WriteConnectError(rec, New("boom"))
+
+		if rec.Code != http.StatusInternalServerError {
+			t.Fatalf("\n got:  %v\n want: %v", rec.Code, http.StatusInternalServerError)
+		}
+
+		want := `{"code":"internal","message":"boom"}`
+		if got := rec.Body.String(); got != want {
+			t.Fatalf("\n got:  %v\n want: %v", got, want)
+		}
+	})
+}
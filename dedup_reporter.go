@@ -0,0 +1,197 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DedupConfig configures a DedupReporter. Zero values use the
+// documented defaults.
+type DedupConfig struct {
+	// Window is the duration the identical errors are counted
+	// over, and the interval of the summaries. Defaults to
+	// 1 minute.
+	Window time.Duration
+
+	// Threshold is the number of identical errors reported
+	// within a window before they are sampled. Defaults to 1,
+	// only the first one is reported.
+	Threshold int
+
+	// SampleRate is the fraction, between 0 and 1, of the
+	// identical errors reported beyond the threshold. None of
+	// them are reported by default.
+	SampleRate float64
+}
+
+// DedupReporter wraps a Reporter to deduplicate the errors
+// with the same Fingerprint within a window: the errors beyond
+// the threshold are sampled and the other ones suppressed.
+// It is safe for concurrent use.
+//
+// At the end of every window, a summary of each suppressed
+// error is reported to the wrapped Reporter as a GenericError
+// named "suppressed_errors". It wraps the first error of the
+// window and has an ErrorInfo detail with the "SUPPRESSED"
+// reason, along with the fingerprint, the number of errors and
+// the number of suppressed errors as metadata. It is reported
+// with the context fields of the first error.
+type DedupReporter struct {
+	r      Reporter
+	cfg    DedupConfig
+	now    func() time.Time
+	random func() float64
+
+	mu      sync.Mutex
+	entries map[string]*dedupEntry
+
+	done      chan struct{}
+	stopped   chan struct{}
+	closeOnce sync.Once
+}
+
+// dedupEntry counts the errors of a fingerprint in a window.
+type dedupEntry struct {
+	start      time.Time
+	err        error
+	fields     map[string]string
+	count      int
+	suppressed int
+}
+
+// NewDedupReporter creates a new DedupReporter wrapping r and
+// starts the goroutine reporting the summaries, which is
+// stopped by Close.
+func NewDedupReporter(r Reporter, cfg DedupConfig) *DedupReporter {
+	if cfg.Window <= 0 {
+		cfg.Window = time.Minute
+	}
+	if cfg.Threshold <= 0 {
+		cfg.Threshold = 1
+	}
+
+	d := &DedupReporter{
+		r:       r,
+		cfg:     cfg,
+		now:     time.Now,
+		random:  rand.Float64,
+		entries: map[string]*dedupEntry{},
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	go d.run()
+
+	return d
+}
+
+// Report reports the error to the wrapped Reporter unless it
+// is suppressed. It reports whether the error was accepted,
+// which is false for the suppressed errors.
+func (d *DedupReporter) Report(ctx context.Context, err error) bool {
+	if err == nil {
+		return false
+	}
+
+	fp := Fingerprint(err)
+	now := d.now()
+
+	d.mu.Lock()
+	var summary *dedupEntry
+	e := d.entries[fp]
+	if e == nil || now.Sub(e.start) >= d.cfg.Window {
+		if e != nil && e.suppressed > 0 {
+			summary = e
+		}
+		e = &dedupEntry{start: now, err: err, fields: FieldsFromContext(ctx)}
+		d.entries[fp] = e
+	}
+
+	e.count++
+	report := e.count <= d.cfg.Threshold || d.random() < d.cfg.SampleRate
+	if !report {
+		e.suppressed++
+	}
+	d.mu.Unlock()
+
+	if summary != nil {
+		d.reportSummary(fp, summary)
+	}
+
+	if !report {
+		return false
+	}
+
+	return d.r.Report(ctx, err)
+}
+
+// Close stops the goroutine reporting the summaries and
+// reports the summaries of the current windows.
+func (d *DedupReporter) Close() {
+	d.closeOnce.Do(func() {
+		close(d.done)
+		<-d.stopped
+		d.sweep(true)
+	})
+}
+
+func (d *DedupReporter) run() {
+	defer close(d.stopped)
+
+	ticker := time.NewTicker(d.cfg.Window)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-d.done:
+			return
+		case <-ticker.C:
+			d.sweep(false)
+		}
+	}
+}
+
+// sweep removes the windows that ended, or all of them, and
+// reports their summaries.
+func (d *DedupReporter) sweep(all bool) {
+	now := d.now()
+	summaries := map[string]*dedupEntry{}
+
+	d.mu.Lock()
+	for fp, e := range d.entries {
+		if !all && now.Sub(e.start) < d.cfg.Window {
+			continue
+		}
+
+		delete(d.entries, fp)
+		if e.suppressed > 0 {
+			summaries[fp] = e
+		}
+	}
+	d.mu.Unlock()
+
+	for fp, e := range summaries {
+		d.reportSummary(fp, e)
+	}
+}
+
+func (d *DedupReporter) reportSummary(fp string, e *dedupEntry) {
+	err := &GenericError{
+		Name:     "suppressed_errors",
+		Message:  fmt.Sprintf("%d of %d identical errors suppressed within %s", e.suppressed, e.count, d.cfg.Window),
+		Original: e.err,
+		Details: Details{ErrorInfo{
+			Reason: "SUPPRESSED",
+			Metadata: map[string]string{
+				"fingerprint": fp,
+				"count":       strconv.Itoa(e.count),
+				"suppressed":  strconv.Itoa(e.suppressed),
+			},
+		}},
+	}
+
+	d.r.Report(ContextWithFields(context.Background(), e.fields), err)
+}
@@ -0,0 +1,245 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// collectingReporter is a Reporter collecting the errors and
+// the fields of their context.
+type collectingReporter struct {
+	mu     sync.Mutex
+	errs   []error
+	fields []map[string]string
+}
+
+func (r *collectingReporter) Report(ctx context.Context, err error) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.errs = append(r.errs, err)
+	r.fields = append(r.fields, FieldsFromContext(ctx))
+	return true
+}
+
+func (r *collectingReporter) reported() ([]error, []map[string]string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]error(nil), r.errs...), append([]map[string]string(nil), r.fields...)
+}
+
+// newTestDedupReporter returns a reporter wrapping a
+// collectingReporter and a pointer to it's clock.
+func newTestDedupReporter(t *testing.T, cfg DedupConfig) (*DedupReporter, *collectingReporter, *time.Time) {
+	t.Helper()
+
+	r := &collectingReporter{}
+	d := NewDedupReporter(r, cfg)
+	t.Cleanup(d.Close)
+
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	d.now = func() time.Time { return now }
+
+	return d, r, &now
+}
+
+func badGateway(i int) error {
+	return NewBadGatewayError(fmt.Sprintf("upstream request %d failed", i), nil)
+}
+
+func TestDedupReporter(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("should report the identical errors once per window", func(t *testing.T) {
+		d, r, _ := newTestDedupReporter(t, DedupConfig{})
+
+		for i := range 5 {
+			if got, want := d.Report(ctx, badGateway(i)), i == 0; got != want {
+				t.Fatalf("report %d:\n got:  %v\n want: %v", i, got, want)
+			}
+		}
+		d.Report(ctx, NewNotFoundError("not found", nil))
+
+		errs, _ := r.reported()
+		if len(errs) != 2 || errs[0].Error() != "bad_gateway_error: upstream request 0 failed" ||
+			errs[1].Error() != "not_found_error: not found" {
+			t.Fatalf("unexpected reported errors: %v", errs)
+		}
+	})
+
+	t.Run("should report the errors up to the threshold", func(t *testing.T) {
+		d, r, _ := newTestDedupReporter(t, DedupConfig{Threshold: 3})
+
+		for i := range 5 {
+			d.Report(ctx, badGateway(i))
+		}
+
+		if errs, _ := r.reported(); len(errs) != 3 {
+			t.Fatalf("expected 3 reported errors, got %v", errs)
+		}
+	})
+
+	t.Run("should sample the errors beyond the threshold", func(t *testing.T) {
+		d, r, _ := newTestDedupReporter(t, DedupConfig{Threshold: 1, SampleRate: 0.5})
+
+		i := 0
+		d.random = func() float64 {
+			i++
+			return float64(i%2) * 0.9
+		}
+
+		for i := range 9 {
+			d.Report(ctx, badGateway(i))
+		}
+
+		if errs, _ := r.reported(); len(errs) != 5 {
+			t.Fatalf("expected 5 reported errors, got %v", errs)
+		}
+	})
+
+	t.Run("should report a summary of the suppressed errors", func(t *testing.T) {
+		d, r, now := newTestDedupReporter(t, DedupConfig{Window: time.Minute})
+
+		reqCtx := ContextWithRequestID(ctx, "abc")
+		for i := range 3 {
+			d.Report(reqCtx, badGateway(i))
+		}
+
+		*now = now.Add(59 * time.Second)
+		d.sweep(false)
+		if errs, _ := r.reported(); len(errs) != 1 {
+			t.Fatalf("expected no summary within the window, got %v", errs)
+		}
+
+		*now = now.Add(time.Second)
+		d.sweep(false)
+
+		errs, fields := r.reported()
+		if len(errs) != 2 {
+			t.Fatalf("expected a summary, got %v", errs)
+		}
+
+		summary, ok := errs[1].(*GenericError)
+		if !ok || summary.Name != "suppressed_errors" || summary.Message != "2 of 3 identical errors suppressed within 1m0s" {
+			t.Fatalf("unexpected summary: %v", errs[1])
+		}
+		if summary.Original != errs[0] {
+			t.Fatalf("expected the summary to wrap the first error, got %v", summary.Original)
+		}
+
+		info, ok := FindDetail[ErrorInfo](summary)
+		want := map[string]string{"fingerprint": Fingerprint(errs[0]), "count": "3", "suppressed": "2"}
+		if !ok || info.Reason != "SUPPRESSED" || !reflect.DeepEqual(info.Metadata, want) {
+			t.Fatalf("\n got:  %v\n want: %v", info.Metadata, want)
+		}
+
+		if fields[1]["requestId"] != "abc" {
+			t.Fatalf("expected the summary to have the fields of the first error, got %v", fields[1])
+		}
+
+		// the window was removed
+		d.Report(ctx, badGateway(4))
+		if errs, _ := r.reported(); len(errs) != 3 {
+			t.Fatalf("expected the error to be reported in a new window, got %v", errs)
+		}
+	})
+
+	t.Run("should not report a summary without suppressed errors", func(t *testing.T) {
+		d, r, now := newTestDedupReporter(t, DedupConfig{Threshold: 2})
+
+		d.Report(ctx, badGateway(1))
+		d.Report(ctx, badGateway(2))
+		*now = now.Add(time.Minute)
+		d.sweep(false)
+
+		if errs, _ := r.reported(); len(errs) != 2 {
+			t.Fatalf("unexpected reported errors: %v", errs)
+		}
+	})
+
+	t.Run("should report the summary when a new window starts", func(t *testing.T) {
+		d, r, now := newTestDedupReporter(t, DedupConfig{})
+
+		d.Report(ctx, badGateway(1))
+		d.Report(ctx, badGateway(2))
+		*now = now.Add(time.Minute)
+		d.Report(ctx, badGateway(3))
+
+		errs, _ := r.reported()
+		if len(errs) != 3 || errs[1].(*GenericError).Name != "suppressed_errors" ||
+			errs[2].Error() != "bad_gateway_error: upstream request 3 failed" {
+			t.Fatalf("unexpected reported errors: %v", errs)
+		}
+	})
+
+	t.Run("should report the pending summaries on close", func(t *testing.T) {
+		d, r, _ := newTestDedupReporter(t, DedupConfig{})
+
+		d.Report(ctx, badGateway(1))
+		d.Report(ctx, badGateway(2))
+		d.Close()
+		d.Close()
+
+		if errs, _ := r.reported(); len(errs) != 2 || errs[1].(*GenericError).Name != "suppressed_errors" {
+			t.Fatalf("unexpected reported errors: %v", errs)
+		}
+	})
+
+	t.Run("should report the summaries periodically", func(t *testing.T) {
+		r := &collectingReporter{}
+		d := NewDedupReporter(r, DedupConfig{Window: 10 * time.Millisecond})
+		defer d.Close()
+
+		d.Report(ctx, badGateway(1))
+		d.Report(ctx, badGateway(2))
+
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			if errs, _ := r.reported(); len(errs) == 2 {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("timed out waiting for the summary")
+			}
+			time.Sleep(time.Millisecond)
+		}
+	})
+
+	t.Run("should be safe for concurrent use", func(t *testing.T) {
+		r := &collectingReporter{}
+		d := NewDedupReporter(r, DedupConfig{Threshold: 10, SampleRate: 0.1})
+
+		var wg sync.WaitGroup
+		for i := range 50 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := range 20 {
+					d.Report(ctx, badGateway(i*20+j))
+				}
+			}()
+		}
+		wg.Wait()
+		d.Close()
+
+		errs, _ := r.reported()
+		summary, ok := errs[len(errs)-1].(*GenericError)
+		if !ok || summary.Name != "suppressed_errors" {
+			t.Fatalf("expected a summary, got %v", errs[len(errs)-1])
+		}
+
+		info, _ := FindDetail[ErrorInfo](summary)
+		suppressed, _ := strconv.Atoi(info.Metadata["suppressed"])
+		if got, want := len(errs)-1+suppressed, 1000; got != want {
+			t.Fatalf("\n got:  %v\n want: %v", got, want)
+		}
+	})
+}
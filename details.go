@@ -0,0 +1,338 @@
+package errors
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Detail is an interface for typed, machine-readable
+// error details that can be attached to an error.
+//
+// Details are modelled after the well-known google.rpc
+// error details and are tagged with their type URL under
+// the "@type" key when marshalled to JSON.
+type Detail interface {
+	// TypeURL returns the type identifier of the detail
+	// that is written to the "@type" JSON key.
+	TypeURL() string
+}
+
+// Details is a list of error details. It marshals every
+// detail with its "@type" key and unmarshals them back to
+// their registered types.
+type Details []Detail
+
+var (
+	detailsMu       sync.RWMutex
+	detailDecoders  = map[string]func([]byte) (Detail, error){}
+	errInvalidEntry = New("error detail is not a JSON object")
+)
+
+func init() {
+	RegisterDetail[ErrorInfo]()
+	RegisterDetail[RetryInfo]()
+	RegisterDetail[QuotaFailure]()
+	RegisterDetail[Help]()
+	RegisterDetail[LocalizedMessage]()
+}
+
+// RegisterDetail registers the detail type T so it can be
+// recovered by it's type URL when decoding Details. Registering
+// a type URL that is already registered replaces the previous
+// registration. T can be a pointer type, the type URL is then
+// the one of a pointer to a zero value.
+func RegisterDetail[T Detail]() {
+	var zero T
+	if t := reflect.TypeFor[T](); t.Kind() == reflect.Pointer {
+		zero = reflect.New(t.Elem()).Interface().(T)
+	}
+
+	detailsMu.Lock()
+	defer detailsMu.Unlock()
+
+	detailDecoders[zero.TypeURL()] = func(b []byte) (Detail, error) {
+		var d T
+		if err := json.Unmarshal(b, &d); err != nil {
+			return nil, err
+		}
+
+		return d, nil
+	}
+}
+
+// MarshalJSON marshals every detail as a JSON object with
+// it's type URL under the "@type" key.
+func (d Details) MarshalJSON() ([]byte, error) {
+	buf := bytes.NewBufferString("[")
+	for i, detail := range d {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		b, err := marshalDetail(detail)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(b)
+	}
+	buf.WriteByte(']')
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON unmarshals the details into their registered
+// types based on the "@type" key. Details with an unknown type
+// are decoded as a RawDetail.
+func (d *Details) UnmarshalJSON(b []byte) error {
+	var raws []json.RawMessage
+	if err := json.Unmarshal(b, &raws); err != nil {
+		return err
+	}
+
+	details := make(Details, 0, len(raws))
+	for _, raw := range raws {
+		detail, err := unmarshalDetail(raw)
+		if err != nil {
+			return err
+		}
+		details = append(details, detail)
+	}
+
+	*d = details
+	return nil
+}
+
+func marshalDetail(detail Detail) ([]byte, error) {
+	if raw, ok := detail.(RawDetail); ok {
+		return raw.MarshalJSON()
+	}
+
+	typ, err := json.Marshal(detail.TypeURL())
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := json.Marshal(detail)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(b) < 2 || b[0] != '{' {
+		return nil, errInvalidEntry
+	}
+
+	out := append([]byte(`{"@type":`), typ...)
+	if string(b) != "{}" {
+		out = append(out, ',')
+	}
+
+	return append(out, b[1:]...), nil
+}
+
+func unmarshalDetail(raw json.RawMessage) (Detail, error) {
+	var head struct {
+		Type string `json:"@type"`
+	}
+	if err := json.Unmarshal(raw, &head); err != nil {
+		return nil, err
+	}
+
+	detailsMu.RLock()
+	decode, ok := detailDecoders[head.Type]
+	detailsMu.RUnlock()
+
+	if !ok {
+		return RawDetail{Type: head.Type, Value: append(json.RawMessage(nil), raw...)}, nil
+	}
+
+	return decode(raw)
+}
+
+// FindDetail returns the first detail of type T found in the
+// given error or in any of the errors it wraps.
+func FindDetail[T Detail](err error) (T, bool) {
+	for ; err != nil; err = unwrapOnce(err) {
+		for _, detail := range detailsOf(err) {
+			if d, ok := detail.(T); ok {
+				return d, true
+			}
+		}
+	}
+
+	var zero T
+	return zero, false
+}
+
+func detailsOf(err error) Details {
+	switch e := err.(type) {
+	case *GenericError:
+		return e.Details
+	case GenericError:
+		return e.Details
+	case *HTTPError:
+		return e.Details
+	}
+
+	return nil
+}
+
+// unwrapOnce returns the error wrapped by err. It knows about
+// the Original and Err properties of the errors of this package
+// and falls back to the standard Unwrap method.
+func unwrapOnce(err error) error {
+	switch e := err.(type) {
+	case *GenericError:
+		return e.Original
+	case GenericError:
+		return e.Original
+	case *HTTPError:
+		return e.Err
+	case interface{ Unwrap() error }:
+		return e.Unwrap()
+	}
+
+	return nil
+}
+
+// RawDetail is a detail whose type is not registered. It keeps
+// the original JSON object so it can be marshalled back as is.
+type RawDetail struct {
+	Type  string
+	Value json.RawMessage
+}
+
+// TypeURL returns the type URL found in the "@type" key.
+func (d RawDetail) TypeURL() string { return d.Type }
+
+// MarshalJSON returns the original JSON object.
+func (d RawDetail) MarshalJSON() ([]byte, error) {
+	if len(d.Value) == 0 {
+		typ, err := json.Marshal(d.Type)
+		if err != nil {
+			return nil, err
+		}
+
+		return append(append([]byte(`{"@type":`), typ...), '}'), nil
+	}
+
+	return d.Value, nil
+}
+
+// ErrorInfo describes the cause of the error with structured
+// details.
+type ErrorInfo struct {
+	// Reason is a short, constant, UPPER_SNAKE_CASE identifier
+	// of the proximate cause of the error.
+	Reason string `json:"reason"`
+
+	// Domain is the logical grouping to which the reason
+	// belongs, usually the service name.
+	Domain string `json:"domain,omitempty"`
+
+	// Metadata is additional structured details about the error.
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// TypeURL returns the ErrorInfo type URL.
+func (ErrorInfo) TypeURL() string { return "type.googleapis.com/google.rpc.ErrorInfo" }
+
+// RetryInfo describes when the client may retry a failed request.
+type RetryInfo struct {
+	// RetryDelay is the time the client should wait
+	// before retrying the request.
+	RetryDelay time.Duration `json:"retryDelay"`
+}
+
+// TypeURL returns the RetryInfo type URL.
+func (RetryInfo) TypeURL() string { return "type.googleapis.com/google.rpc.RetryInfo" }
+
+// MarshalJSON marshals the retry delay as a duration string
+// in seconds (e.g. "1.5s").
+func (d RetryInfo) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		RetryDelay string `json:"retryDelay"`
+	}{
+		RetryDelay: strconv.FormatFloat(d.RetryDelay.Seconds(), 'f', -1, 64) + "s",
+	})
+}
+
+// UnmarshalJSON unmarshals a retry delay duration string
+// in seconds (e.g. "1.5s").
+func (d *RetryInfo) UnmarshalJSON(b []byte) error {
+	var v struct {
+		RetryDelay string `json:"retryDelay"`
+	}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+
+	if v.RetryDelay == "" {
+		d.RetryDelay = 0
+		return nil
+	}
+
+	secs, err := strconv.ParseFloat(strings.TrimSuffix(v.RetryDelay, "s"), 64)
+	if err != nil {
+		return NewWithNameAndErr("error", fmt.Sprintf("invalid retryDelay %q", v.RetryDelay), err)
+	}
+
+	d.RetryDelay = time.Duration(secs * float64(time.Second))
+	return nil
+}
+
+// QuotaFailure describes how a quota check failed.
+type QuotaFailure struct {
+	// Violations describes all quota violations.
+	Violations []QuotaViolation `json:"violations"`
+}
+
+// QuotaViolation describes a single quota violation.
+type QuotaViolation struct {
+	// Subject is the subject on which the quota check
+	// failed (e.g. "clientip:1.2.3.4").
+	Subject string `json:"subject"`
+
+	// Description describes how the quota check failed.
+	Description string `json:"description"`
+}
+
+// TypeURL returns the QuotaFailure type URL.
+func (QuotaFailure) TypeURL() string { return "type.googleapis.com/google.rpc.QuotaFailure" }
+
+// Help provides links to documentation or for performing
+// an out of band action.
+type Help struct {
+	// Links are URLs pointing to additional information.
+	Links []HelpLink `json:"links"`
+}
+
+// HelpLink describes a URL link.
+type HelpLink struct {
+	// Description describes what the link offers.
+	Description string `json:"description"`
+
+	// URL is the URL of the link.
+	URL string `json:"url"`
+}
+
+// TypeURL returns the Help type URL.
+func (Help) TypeURL() string { return "type.googleapis.com/google.rpc.Help" }
+
+// LocalizedMessage provides an error message that is safe
+// to return to the user in the given locale.
+type LocalizedMessage struct {
+	// Locale is the BCP-47 locale of the message (e.g. "en-US").
+	Locale string `json:"locale"`
+
+	// Message is the localized error message.
+	Message string `json:"message"`
+}
+
+// TypeURL returns the LocalizedMessage type URL.
+func (LocalizedMessage) TypeURL() string { return "type.googleapis.com/google.rpc.LocalizedMessage" }
@@ -0,0 +1,167 @@
+package errors
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDetails_MarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should tag every detail with its type", func(t *testing.T) {
+		details := Details{
+			ErrorInfo{Reason: "USER_DISABLED", Domain: "users"},
+			RetryInfo{RetryDelay: 1500 * time.Millisecond},
+			QuotaFailure{Violations: []QuotaViolation{{Subject: "clientip:1.2.3.4", Description: "daily limit"}}},
+			Help{Links: []HelpLink{{Description: "docs", URL: "https://example.com"}}},
+			LocalizedMessage{Locale: "en-US", Message: "user disabled"},
+		}
+		want := `[` +
+			`{"@type":"type.googleapis.com/google.rpc.ErrorInfo","reason":"USER_DISABLED","domain":"users"},` +
+			`{"@type":"type.googleapis.com/google.rpc.RetryInfo","retryDelay":"1.5s"},` +
+			`{"@type":"type.googleapis.com/google.rpc.QuotaFailure","violations":[{"subject":"clientip:1.2.3.4","description":"daily limit"}]},` +
+			`{"@type":"type.googleapis.com/google.rpc.Help","links":[{"description":"docs","url":"https://example.com"}]},` +
+			`{"@type":"type.googleapis.com/google.rpc.LocalizedMessage","locale":"en-US","message":"user disabled"}` +
+			`]`
+
+		b, err := json.Marshal(details)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if got := string(b); got != want {
+			t.Fatalf("\n got:  %v\n want: %v", got, want)
+		}
+	})
+}
+
+func TestDetails_UnmarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should recover details by type", func(t *testing.T) {
+		want := Details{
+			ErrorInfo{Reason: "USER_DISABLED", Metadata: map[string]string{"id": "1"}},
+			RetryInfo{RetryDelay: 2 * time.Second},
+			LocalizedMessage{Locale: "es-CL", Message: "usuario deshabilitado"},
+		}
+		b, err := json.Marshal(want)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		var got Details
+		if err := json.Unmarshal(b, &got); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("\n got:  %#v\n want: %#v", got, want)
+		}
+	})
+
+	t.Run("should keep unknown details as raw details", func(t *testing.T) {
+		in := `[{"@type":"example.com/Unknown","foo":"bar"}]`
+
+		var got Details
+		if err := json.Unmarshal([]byte(in), &got); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if raw, ok := got[0].(RawDetail); !ok || raw.Type != "example.com/Unknown" {
+			t.Fatalf("expected a RawDetail, got %#v", got[0])
+		}
+
+		b, _ := json.Marshal(got)
+		if string(b) != in {
+			t.Fatalf("\n got:  %v\n want: %v", string(b), in)
+		}
+	})
+
+	t.Run("should fail on an invalid retry delay", func(t *testing.T) {
+		in := `[{"@type":"type.googleapis.com/google.rpc.RetryInfo","retryDelay":"soon"}]`
+
+		var got Details
+		if err := json.Unmarshal([]byte(in), &got); err == nil {
+			t.Fatalf("expected an error")
+		}
+	})
+}
+
+// pointerDetail is a detail registered as a pointer type.
+type pointerDetail struct {
+	Value string `json:"value"`
+}
+
+func (pointerDetail) TypeURL() string { return "example.com/PointerDetail" }
+
+func TestRegisterDetail(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should register a pointer detail type", func(t *testing.T) {
+		RegisterDetail[*pointerDetail]()
+
+		var got Details
+		if err := json.Unmarshal([]byte(`[{"@type":"example.com/PointerDetail","value":"a"}]`), &got); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if want := (Details{&pointerDetail{Value: "a"}}); !reflect.DeepEqual(got, want) {
+			t.Fatalf("\n got:  %#v\n want: %#v", got, want)
+		}
+	})
+}
+
+func TestFindDetail(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should find a detail in a wrapped error", func(t *testing.T) {
+		inner := New("inner").(*GenericError).WithDetails(RetryInfo{RetryDelay: time.Second})
+		err := NewServiceUnavailableError("unavailable", inner)
+
+		got, ok := FindDetail[RetryInfo](err)
+		if !ok {
+			t.Fatalf("expected detail to be found")
+		}
+
+		if got.RetryDelay != time.Second {
+			t.Fatalf("\n got:  %v\n want: %v", got.RetryDelay, time.Second)
+		}
+	})
+
+	t.Run("should return false when the detail is missing", func(t *testing.T) {
+		if _, ok := FindDetail[Help](New("error")); ok {
+			t.Fatalf("expected detail not to be found")
+		}
+	})
+}
+
+func TestHTTPError_WithDetails(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should round trip details through JSON", func(t *testing.T) {
+		err := NewNotFoundError("user not found", nil).(*HTTPError).
+			WithDetails(ErrorInfo{Reason: "USER_NOT_FOUND", Domain: "users"})
+
+		got, decodeErr := FromJSON(err.JSON())
+		if decodeErr != nil {
+			t.Fatalf("unexpected error: %v", decodeErr)
+		}
+
+		httpErr, ok := got.(*HTTPError)
+		if !ok {
+			t.Fatalf("expected error to be of type HTTPError, got %T", got)
+		}
+
+		if httpErr.StatusCode != http.StatusNotFound {
+			t.Fatalf("\n got:  %v\n want: %v", httpErr.StatusCode, http.StatusNotFound)
+		}
+
+		info, ok := FindDetail[ErrorInfo](httpErr)
+		if !ok || info.Reason != "USER_NOT_FOUND" {
+			t.Fatalf("expected ErrorInfo detail, got %#v", httpErr.Details)
+		}
+	})
+}
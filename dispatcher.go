@@ -0,0 +1,317 @@
+package errors
+
+import (
+	"context"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DropPolicy defines what a Dispatcher does with the reported
+// errors when it's queue is full.
+type DropPolicy int
+
+const (
+	// DropNewest drops the reported error.
+	DropNewest DropPolicy = iota
+
+	// DropOldest drops the oldest queued error to make room
+	// for the reported one.
+	DropOldest
+
+	// Block blocks the reporting until there is room in the
+	// queue, the context of the report is done or the
+	// Dispatcher is shut down.
+	Block
+)
+
+// DispatcherConfig configures a Dispatcher. Zero values use
+// the documented defaults.
+type DispatcherConfig struct {
+	// QueueSize is the maximum number of queued errors.
+	// Defaults to 1024.
+	QueueSize int
+
+	// BatchSize is the maximum number of errors written to
+	// the sink at once. Defaults to 100.
+	BatchSize int
+
+	// FlushInterval is the maximum time an error is queued
+	// before being written. Defaults to 1 second.
+	FlushInterval time.Duration
+
+	// DropPolicy is the behaviour when the queue is full.
+	// Defaults to DropNewest.
+	DropPolicy DropPolicy
+
+	// OnError is called with the errors returned by the sink.
+	OnError func(error)
+
+	// CloseTimeout is the maximum time Close waits for the
+	// queued errors to be written. Defaults to 10 seconds.
+	CloseTimeout time.Duration
+}
+
+// Dispatcher reports errors to a Sink asynchronously. Errors are
+// queued and written in batches by a background goroutine so
+// the reporting never waits for the sink.
+//
+// A Dispatcher must be created with NewDispatcher and closed
+// with Close. It is safe for concurrent use.
+type Dispatcher struct {
+	sink Sink
+	cfg  DispatcherConfig
+
+	// ctx is the context of the sink writes, canceled when
+	// the shutdown deadline is exceeded
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	queue   chan Event
+	flushes chan chan error
+	stop    chan struct{}
+	done    chan struct{}
+
+	// closing is closed when the shutdown starts, to release
+	// the reports blocked on a full queue
+	closing     chan struct{}
+	closingOnce sync.Once
+
+	mu      sync.RWMutex
+	closed  bool
+	dropped atomic.Uint64
+}
+
+// NewDispatcher creates a new Dispatcher writing to the
+// given sink and starts it's background goroutine.
+func NewDispatcher(sink Sink, cfg DispatcherConfig) *Dispatcher {
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 1024
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = time.Second
+	}
+	if cfg.CloseTimeout <= 0 {
+		cfg.CloseTimeout = 10 * time.Second
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	d := &Dispatcher{
+		sink:    sink,
+		cfg:     cfg,
+		ctx:     ctx,
+		cancel:  cancel,
+		queue:   make(chan Event, cfg.QueueSize),
+		flushes: make(chan chan error),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+		closing: make(chan struct{}),
+	}
+	go d.run()
+
+	return d
+}
+
+// Report queues the given error with the fields of ctx. It
+// reports whether the error was queued, errors are dropped
+// according to the drop policy when the queue is full and
+// after the Dispatcher is closed. Nil errors are ignored.
+func (d *Dispatcher) Report(ctx context.Context, err error) bool {
+	if err == nil {
+		return false
+	}
+
+	return d.Send(ctx, NewEvent(ctx, err))
+}
+
+// Send queues the given event, as Report does.
+func (d *Dispatcher) Send(ctx context.Context, e Event) bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if d.closed {
+		d.dropped.Add(1)
+		return false
+	}
+
+	select {
+	case d.queue <- e:
+		return true
+	default:
+	}
+
+	switch d.cfg.DropPolicy {
+	case DropOldest:
+		for {
+			select {
+			case d.queue <- e:
+				return true
+			default:
+			}
+
+			select {
+			case <-d.queue:
+				d.dropped.Add(1)
+			default:
+			}
+		}
+	case Block:
+		select {
+		case d.queue <- e:
+			return true
+		case <-ctx.Done():
+		case <-d.closing:
+		}
+	}
+
+	d.dropped.Add(1)
+	return false
+}
+
+// Dropped returns the number of errors dropped so far.
+func (d *Dispatcher) Dropped() uint64 {
+	return d.dropped.Load()
+}
+
+// Flush writes the queued errors to the sink and flushes the
+// sink if it is a Flusher. It returns the error of the sink or
+// the ctx error when ctx is done first.
+func (d *Dispatcher) Flush(ctx context.Context) error {
+	result := make(chan error, 1)
+
+	select {
+	case d.flushes <- result:
+	case <-d.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close shuts the Dispatcher down as Shutdown does, waiting
+// at most the CloseTimeout of it's config.
+func (d *Dispatcher) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), d.cfg.CloseTimeout)
+	defer cancel()
+
+	return d.Shutdown(ctx)
+}
+
+// Shutdown stops accepting errors, writes the queued ones to
+// the sink, flushes it if it is a Flusher and closes it if it
+// is an io.Closer. It waits for the background goroutine to
+// return.
+//
+// When ctx is done first, the context of the sink writes is
+// canceled and the ctx error is returned without waiting for
+// the background goroutine, the sink is then not closed.
+func (d *Dispatcher) Shutdown(ctx context.Context) error {
+	// the blocked reports are released before taking the lock
+	// they hold
+	d.closingOnce.Do(func() { close(d.closing) })
+
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		select {
+		case <-d.done:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	d.closed = true
+	d.mu.Unlock()
+
+	close(d.stop)
+	select {
+	case <-d.done:
+	case <-ctx.Done():
+		d.cancel()
+		return ctx.Err()
+	}
+	defer d.cancel()
+
+	err := d.flushSink()
+	if c, ok := d.sink.(io.Closer); ok {
+		if closeErr := c.Close(); err == nil {
+			err = closeErr
+		}
+	}
+
+	return err
+}
+
+func (d *Dispatcher) run() {
+	defer close(d.done)
+
+	ticker := time.NewTicker(d.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]Event, 0, d.cfg.BatchSize)
+	for {
+		select {
+		case e := <-d.queue:
+			batch = append(batch, e)
+			if len(batch) >= d.cfg.BatchSize {
+				batch = d.write(batch)
+			}
+		case <-ticker.C:
+			batch = d.write(batch)
+		case result := <-d.flushes:
+			batch = d.drain(batch)
+			result <- d.flushSink()
+		case <-d.stop:
+			d.drain(batch)
+			return
+		}
+	}
+}
+
+// drain writes the batch and every queued error.
+func (d *Dispatcher) drain(batch []Event) []Event {
+	for {
+		select {
+		case e := <-d.queue:
+			batch = append(batch, e)
+			if len(batch) >= d.cfg.BatchSize {
+				batch = d.write(batch)
+			}
+		default:
+			return d.write(batch)
+		}
+	}
+}
+
+// write writes the batch to the sink and returns it emptied.
+func (d *Dispatcher) write(batch []Event) []Event {
+	if len(batch) == 0 {
+		return batch
+	}
+
+	if err := d.sink.Write(d.ctx, batch); err != nil && d.cfg.OnError != nil {
+		d.cfg.OnError(err)
+	}
+
+	clear(batch)
+	return batch[:0]
+}
+
+func (d *Dispatcher) flushSink() error {
+	if f, ok := d.sink.(Flusher); ok {
+		return f.Flush()
+	}
+
+	return nil
+}
@@ -0,0 +1,380 @@
+package errors
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memorySink records the written batches. When block is not
+// nil, writes wait until it is closed.
+type memorySink struct {
+	mu      sync.Mutex
+	batches [][]Event
+	flushes int
+	closed  bool
+	block   chan struct{}
+	err     error
+}
+
+func (s *memorySink) Write(_ context.Context, events []Event) error {
+	if s.block != nil {
+		<-s.block
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.batches = append(s.batches, append([]Event(nil), events...))
+	return s.err
+}
+
+func (s *memorySink) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.flushes++
+	return nil
+}
+
+func (s *memorySink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	return nil
+}
+
+func (s *memorySink) messages() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var msgs []string
+	for _, batch := range s.batches {
+		for _, e := range batch {
+			msgs = append(msgs, e.Err.(*GenericError).Message)
+		}
+	}
+
+	return msgs
+}
+
+func TestDispatcher(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("should write batches of the configured size", func(t *testing.T) {
+		sink := &memorySink{}
+		d := NewDispatcher(sink, DispatcherConfig{BatchSize: 2, FlushInterval: time.Hour})
+
+		for _, msg := range []string{"a", "b", "c"} {
+			if !d.Report(ctx, New(msg)) {
+				t.Fatalf("expected %q to be queued", msg)
+			}
+		}
+
+		if err := d.Flush(ctx); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		sink.mu.Lock()
+		sizes := []int{}
+		for _, b := range sink.batches {
+			sizes = append(sizes, len(b))
+		}
+		flushes := sink.flushes
+		sink.mu.Unlock()
+
+		if len(sizes) != 2 || sizes[0] != 2 || sizes[1] != 1 {
+			t.Fatalf("\n got:  %v\n want: %v", sizes, []int{2, 1})
+		}
+
+		if flushes != 1 {
+			t.Fatalf("expected the sink to be flushed once, got %d", flushes)
+		}
+
+		if err := d.Close(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("should write periodically", func(t *testing.T) {
+		sink := &memorySink{}
+		d := NewDispatcher(sink, DispatcherConfig{FlushInterval: 10 * time.Millisecond})
+		defer d.Close()
+
+		d.Report(ctx, New("a"))
+
+		deadline := time.Now().Add(time.Second)
+		for len(sink.messages()) == 0 {
+			if time.Now().After(deadline) {
+				t.Fatalf("expected the error to be written")
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	})
+
+	t.Run("should drop the newest errors when full", func(t *testing.T) {
+		sink := &memorySink{block: make(chan struct{})}
+		d := NewDispatcher(sink, DispatcherConfig{QueueSize: 2, BatchSize: 1, FlushInterval: time.Hour})
+
+		// the first error is held by the blocked sink
+		d.Report(ctx, New("a"))
+		waitQueueLen(t, d, 0)
+
+		for _, msg := range []string{"b", "c", "d"} {
+			d.Report(ctx, New(msg))
+		}
+
+		close(sink.block)
+		if err := d.Close(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if got, want := sink.messages(), []string{"a", "b", "c"}; !slices.Equal(got, want) {
+			t.Fatalf("\n got:  %v\n want: %v", got, want)
+		}
+
+		if got := d.Dropped(); got != 1 {
+			t.Fatalf("\n got:  %v\n want: %v", got, 1)
+		}
+	})
+
+	t.Run("should drop the oldest errors when full", func(t *testing.T) {
+		sink := &memorySink{block: make(chan struct{})}
+		d := NewDispatcher(sink, DispatcherConfig{QueueSize: 2, BatchSize: 1, FlushInterval: time.Hour, DropPolicy: DropOldest})
+
+		d.Report(ctx, New("a"))
+		waitQueueLen(t, d, 0)
+
+		for _, msg := range []string{"b", "c", "d"} {
+			d.Report(ctx, New(msg))
+		}
+
+		close(sink.block)
+		_ = d.Close()
+
+		if got, want := sink.messages(), []string{"a", "c", "d"}; !slices.Equal(got, want) {
+			t.Fatalf("\n got:  %v\n want: %v", got, want)
+		}
+	})
+
+	t.Run("should block until the context is done when full", func(t *testing.T) {
+		sink := &memorySink{block: make(chan struct{})}
+		d := NewDispatcher(sink, DispatcherConfig{QueueSize: 1, BatchSize: 1, FlushInterval: time.Hour, DropPolicy: Block})
+
+		d.Report(ctx, New("a"))
+		waitQueueLen(t, d, 0)
+		d.Report(ctx, New("b"))
+
+		timeoutCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		if d.Report(timeoutCtx, New("c")) {
+			t.Fatalf("expected the error to be dropped")
+		}
+		if time.Since(start) < 20*time.Millisecond {
+			t.Fatalf("expected the report to block")
+		}
+
+		close(sink.block)
+		_ = d.Close()
+
+		if got, want := sink.messages(), []string{"a", "b"}; !slices.Equal(got, want) {
+			t.Fatalf("\n got:  %v\n want: %v", got, want)
+		}
+	})
+
+	t.Run("should stop accepting errors once closed", func(t *testing.T) {
+		sink := &memorySink{}
+		d := NewDispatcher(sink, DispatcherConfig{})
+
+		d.Report(ctx, New("a"))
+		if err := d.Close(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if d.Report(ctx, New("b")) {
+			t.Fatalf("expected the error to be dropped")
+		}
+
+		if got, want := sink.messages(), []string{"a"}; !slices.Equal(got, want) {
+			t.Fatalf("\n got:  %v\n want: %v", got, want)
+		}
+
+		if !sink.closed {
+			t.Fatalf("expected the sink to be closed")
+		}
+
+		if err := d.Flush(ctx); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := d.Close(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("should return the context error when the flush times out", func(t *testing.T) {
+		sink := &memorySink{block: make(chan struct{})}
+		d := NewDispatcher(sink, DispatcherConfig{})
+		defer func() {
+			close(sink.block)
+			_ = d.Close()
+		}()
+
+		d.Report(ctx, New("a"))
+
+		timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+
+		if err := d.Flush(timeoutCtx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("\n got:  %v\n want: %v", err, context.DeadlineExceeded)
+		}
+	})
+
+	t.Run("should cancel the sink writes when the shutdown times out", func(t *testing.T) {
+		canceled := make(chan error, 1)
+		sink := sinkFunc(func(ctx context.Context, events []Event) error {
+			<-ctx.Done()
+			canceled <- ctx.Err()
+			return ctx.Err()
+		})
+		d := NewDispatcher(sink, DispatcherConfig{})
+
+		d.Report(ctx, New("a"))
+
+		timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+
+		if err := d.Shutdown(timeoutCtx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("\n got:  %v\n want: %v", err, context.DeadlineExceeded)
+		}
+
+		select {
+		case err := <-canceled:
+			if !errors.Is(err, context.Canceled) {
+				t.Fatalf("\n got:  %v\n want: %v", err, context.Canceled)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("expected the sink write to be canceled")
+		}
+	})
+
+	t.Run("should release the blocked reports when the shutdown times out", func(t *testing.T) {
+		sink := sinkFunc(func(ctx context.Context, events []Event) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		d := NewDispatcher(sink, DispatcherConfig{QueueSize: 1, BatchSize: 1, DropPolicy: Block})
+
+		// the first error is being written and the second one queued
+		d.Report(ctx, New("a"))
+		d.Report(ctx, New("b"))
+
+		reported := make(chan bool, 1)
+		go func() { reported <- d.Report(ctx, New("c")) }()
+		time.Sleep(10 * time.Millisecond)
+
+		timeoutCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+
+		shutdown := make(chan error, 1)
+		go func() { shutdown <- d.Shutdown(timeoutCtx) }()
+
+		select {
+		case err := <-shutdown:
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Fatalf("\n got:  %v\n want: %v", err, context.DeadlineExceeded)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("expected the shutdown to time out")
+		}
+
+		if <-reported {
+			t.Fatalf("expected the blocked error to be dropped")
+		}
+	})
+
+	t.Run("should bound the close with the close timeout", func(t *testing.T) {
+		sink := sinkFunc(func(ctx context.Context, events []Event) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		d := NewDispatcher(sink, DispatcherConfig{CloseTimeout: 10 * time.Millisecond})
+
+		d.Report(ctx, New("a"))
+
+		if err := d.Close(); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("\n got:  %v\n want: %v", err, context.DeadlineExceeded)
+		}
+	})
+
+	t.Run("should report the sink errors", func(t *testing.T) {
+		var mu sync.Mutex
+		var got error
+
+		want := errors.New("sink error")
+		d := NewDispatcher(&memorySink{err: want}, DispatcherConfig{OnError: func(err error) {
+			mu.Lock()
+			got = err
+			mu.Unlock()
+		}})
+
+		d.Report(ctx, New("a"))
+		_ = d.Close()
+
+		mu.Lock()
+		defer mu.Unlock()
+		if got != want {
+			t.Fatalf("\n got:  %v\n want: %v", got, want)
+		}
+	})
+
+	t.Run("should report concurrently", func(t *testing.T) {
+		sink := &memorySink{}
+		d := NewDispatcher(sink, DispatcherConfig{QueueSize: 10000, BatchSize: 7})
+
+		var wg sync.WaitGroup
+		for range 10 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for range 100 {
+					d.Report(ctx, New("a"))
+				}
+			}()
+		}
+		wg.Wait()
+		_ = d.Close()
+
+		if got := len(sink.messages()); got != 1000 {
+			t.Fatalf("\n got:  %v\n want: %v", got, 1000)
+		}
+	})
+}
+
+// sinkFunc is a Sink calling the function.
+type sinkFunc func(ctx context.Context, events []Event) error
+
+func (f sinkFunc) Write(ctx context.Context, events []Event) error {
+	return f(ctx, events)
+}
+
+// waitQueueLen waits for the queue of the dispatcher to have
+// the given length.
+func waitQueueLen(t *testing.T, d *Dispatcher, n int) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for len(d.queue) != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d queued errors, got %d", n, len(d.queue))
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
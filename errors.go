@@ -1,5 +1,10 @@
 package errors
 
+import (
+	"bytes"
+	"encoding/json"
+)
+
 // Error is an interface for errors
 // that can be marshalled to JSON
 type Error interface {
@@ -28,3 +33,56 @@ func ToError(err error) Error {
 
 	return e
 }
+
+// FromJSON decodes the JSON representation of an Error
+// returned by the JSON() method of the errors of this
+// package.
+//
+// The returned error is an *HTTPError if the JSON object
+// has a "statusCode" property, otherwise it is a *GenericError.
+func FromJSON(b []byte) (Error, error) {
+	err, decodeErr := decodeError(b)
+	if decodeErr != nil {
+		return nil, decodeErr
+	}
+
+	if err == nil {
+		return nil, New("given JSON is not an error object")
+	}
+
+	return err.(Error), nil
+}
+
+// decodeError decodes a JSON error object into an *HTTPError
+// or a *GenericError. It returns nil when the given JSON is
+// empty, null or an object that is not an error of this
+// package (e.g. a marshalled error without exported fields).
+func decodeError(b []byte) (error, error) {
+	b = bytes.TrimSpace(b)
+	if len(b) == 0 || bytes.Equal(b, []byte("null")) {
+		return nil, nil
+	}
+
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(b, &keys); err != nil {
+		return nil, err
+	}
+
+	if _, ok := keys["statusCode"]; ok {
+		e := &HTTPError{}
+		if err := json.Unmarshal(b, e); err != nil {
+			return nil, err
+		}
+		return e, nil
+	}
+
+	if _, ok := keys["name"]; ok {
+		e := &GenericError{}
+		if err := json.Unmarshal(b, e); err != nil {
+			return nil, err
+		}
+		return e, nil
+	}
+
+	return nil, nil
+}
@@ -28,3 +28,43 @@ func TestToError(t *testing.T) {
 		}
 	})
 }
+
+func TestFromJSON(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should decode an http error with its chain", func(t *testing.T) {
+		want := NewBadGatewayError("upstream failed", NewWithName("upstream_error", "timeout"))
+		got, err := FromJSON(want.(Error).JSON())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if got.Error() != want.Error() {
+			t.Fatalf("\n got:  %v\n want: %v", got.Error(), want.Error())
+		}
+
+		if _, ok := got.(*HTTPError).Err.(*GenericError); !ok {
+			t.Fatalf("expected inner error to be of type GenericError, got %T", got.(*HTTPError).Err)
+		}
+	})
+
+	t.Run("should decode a generic error", func(t *testing.T) {
+		want := NewWithName("name", "message")
+		got, err := FromJSON(want.(Error).JSON())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if string(got.JSON()) != string(want.(Error).JSON()) {
+			t.Fatalf("\n got:  %v\n want: %v", string(got.JSON()), string(want.(Error).JSON()))
+		}
+	})
+
+	t.Run("should fail when the JSON is not an error", func(t *testing.T) {
+		for _, in := range []string{`null`, `{"foo":"bar"}`, `[]`} {
+			if _, err := FromJSON([]byte(in)); err == nil {
+				t.Fatalf("expected an error for %s", in)
+			}
+		}
+	})
+}
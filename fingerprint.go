@@ -0,0 +1,148 @@
+package errors
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"regexp"
+	"strconv"
+	"sync/atomic"
+)
+
+var (
+	// fingerprintEnabled reports whether JSON() renders the
+	// fingerprint of the errors.
+	fingerprintEnabled atomic.Bool
+
+	// messageNormalizers are the normalizers set with
+	// SetMessageNormalizers, nil until it is called.
+	messageNormalizers atomic.Pointer[[]MessageNormalizer]
+)
+
+// MessageNormalizer replaces the volatile parts of an error
+// message, like IDs or timestamps, before it is fingerprinted.
+type MessageNormalizer func(msg string) string
+
+var (
+	uuidRe      = regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`)
+	timestampRe = regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:?\d{2})?`)
+	hexRe       = regexp.MustCompile(`\b(0x)?[0-9a-fA-F]{8,}\b`)
+	numberRe    = regexp.MustCompile(`\d+`)
+)
+
+// DefaultMessageNormalizer replaces the UUIDs, the timestamps,
+// the hexadecimal numbers of at least 8 digits and the decimal
+// numbers of a message with placeholders.
+func DefaultMessageNormalizer(msg string) string {
+	msg = uuidRe.ReplaceAllString(msg, "<uuid>")
+	msg = timestampRe.ReplaceAllString(msg, "<time>")
+	msg = hexRe.ReplaceAllString(msg, "<hex>")
+
+	return numberRe.ReplaceAllString(msg, "<n>")
+}
+
+// SetMessageNormalizers sets the normalizers applied in order
+// to the messages fingerprinted by Fingerprint, replacing
+// DefaultMessageNormalizer which is used by default. Calling it
+// without normalizers fingerprints the messages as they are.
+func SetMessageNormalizers(normalizers ...MessageNormalizer) {
+	normalizers = append([]MessageNormalizer{}, normalizers...)
+	messageNormalizers.Store(&normalizers)
+}
+
+func normalizeMessage(msg string) string {
+	p := messageNormalizers.Load()
+	if p == nil {
+		return DefaultMessageNormalizer(msg)
+	}
+
+	for _, n := range *p {
+		msg = n(msg)
+	}
+
+	return msg
+}
+
+// EnableFingerprint enables or disables the rendering of the
+// fingerprint of the errors by JSON(), as the "fingerprint"
+// property. It is disabled by default.
+func EnableFingerprint(enabled bool) {
+	fingerprintEnabled.Store(enabled)
+}
+
+// jsonFingerprint returns the fingerprint rendered by JSON(),
+// or an empty string when it is disabled.
+func jsonFingerprint(err error) string {
+	if !fingerprintEnabled.Load() {
+		return ""
+	}
+
+	return Fingerprint(err)
+}
+
+// Fingerprint returns a stable hash identifying the errors
+// that failed the same way, to group or deduplicate them.
+//
+// It hashes the type, the name, the status code and the
+// normalized message of every error of the chain, along with
+// the functions of the stack traces. Line numbers, return
+// traces, sources, details and operations are ignored. The
+// messages of errors wrapping another one that are not a
+// GenericError or an HTTPError are ignored as well since they
+// usually repeat the wrapped message.
+func Fingerprint(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	h := sha256.New()
+	fingerprint(h, err, 0)
+
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+func fingerprint(h hash.Hash, err error, depth int) {
+	for ; err != nil && depth < maxErrorDepth; depth++ {
+		if e, ok := err.(GenericError); ok {
+			err = &e
+		}
+
+		fmt.Fprintf(h, "%T\x00", err)
+		next := unwrapOnce(err)
+
+		switch e := err.(type) {
+		case *GenericError:
+			writeFields(h, e.Name, normalizeMessage(e.Message))
+			for _, f := range e.Stack {
+				writeFields(h, f.Function)
+			}
+		case *HTTPError:
+			writeFields(h, e.Name, strconv.Itoa(e.StatusCode), normalizeMessage(e.Message))
+		case *MultiError:
+			writeFields(h, e.Name, normalizeMessage(e.Message))
+			for _, c := range e.Causes {
+				writeFields(h, c.Label)
+				fingerprint(h, c.Err, depth+1)
+			}
+		case interface{ Unwrap() []error }:
+			for _, err := range e.Unwrap() {
+				fingerprint(h, err, depth+1)
+			}
+		default:
+			if next == nil {
+				writeFields(h, normalizeMessage(err.Error()))
+			}
+		}
+
+		err = next
+	}
+}
+
+// writeFields writes null terminated fields to the hash.
+func writeFields(h hash.Hash, fields ...string) {
+	for _, f := range fields {
+		h.Write([]byte(f))
+		h.Write([]byte{0})
+	}
+}
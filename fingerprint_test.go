@@ -0,0 +1,163 @@
+package errors
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// TestFingerprintConfig is not parallel since it toggles the
+// fingerprint settings, sequential tests run before the
+// parallel ones.
+func TestFingerprintConfig(t *testing.T) {
+	t.Run("should render the fingerprint when enabled", func(t *testing.T) {
+		EnableFingerprint(true)
+		defer EnableFingerprint(false)
+
+		err := NewNotFoundError("user 42 not found", nil)
+		want := fmt.Sprintf(`"fingerprint":%q`, Fingerprint(err))
+
+		for _, e := range []Error{err.(Error), New("boom").(Error), &MultiError{Name: "multi_error", Causes: []Cause{{Err: err}}}} {
+			if got := string(e.JSON()); !strings.Contains(got, `"fingerprint":"`) {
+				t.Fatalf("expected a fingerprint, got %s", got)
+			}
+		}
+
+		if got := string(err.(Error).JSON()); !strings.Contains(got, want) {
+			t.Fatalf("\n got:  %v\n want: %v", got, want)
+		}
+
+		decoded, decodeErr := FromJSON(err.(Error).JSON())
+		if decodeErr != nil || decoded.Error() != err.Error() {
+			t.Fatalf("unexpected decoded error: %v %v", decoded, decodeErr)
+		}
+	})
+
+	t.Run("should not render the fingerprint by default", func(t *testing.T) {
+		if got := string(New("boom").(Error).JSON()); strings.Contains(got, "fingerprint") {
+			t.Fatalf("unexpected fingerprint in %s", got)
+		}
+	})
+
+	t.Run("should apply the configured normalizers", func(t *testing.T) {
+		defer messageNormalizers.Store(nil)
+
+		emails := regexp.MustCompile(`\S+@\S+`)
+		SetMessageNormalizers(DefaultMessageNormalizer, func(msg string) string {
+			return emails.ReplaceAllString(msg, "<email>")
+		})
+
+		if Fingerprint(New("user a@b.com not found")) != Fingerprint(New("user c@d.com not found")) {
+			t.Fatalf("expected the emails to be normalized")
+		}
+
+		SetMessageNormalizers()
+		if Fingerprint(New("user 1 not found")) == Fingerprint(New("user 2 not found")) {
+			t.Fatalf("expected the messages to be fingerprinted as they are")
+		}
+	})
+}
+
+func TestDefaultMessageNormalizer(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should replace the volatile parts", func(t *testing.T) {
+		tests := map[string]string{
+			"user 123 not found": "user <n> not found",
+			"order 3f2504e0-4f89-11d3-9a0c-0305e82c3301 canceled": "order <uuid> canceled",
+			"expired at 2024-01-02T03:04:05.123Z":                 "expired at <time>",
+			"expired at 2024-01-02 03:04:05+01:00":                "expired at <time>",
+			"commit deadbeef0123 missing":                         "commit <hex> missing",
+			"pointer 0xc000012345":                                "pointer <hex>",
+			"no volatile part":                                    "no volatile part",
+		}
+
+		for in, want := range tests {
+			if got := DefaultMessageNormalizer(in); got != want {
+				t.Fatalf("\n got:  %v\n want: %v", got, want)
+			}
+		}
+	})
+}
+
+func TestFingerprint(t *testing.T) {
+	t.Parallel()
+
+	panicAt := func() error {
+		return Safe(func() error { panic("boom") })
+	}
+
+	t.Run("should ignore the volatile parts of the messages", func(t *testing.T) {
+		a := NewNotFoundError("user 42 not found", fmt.Errorf("row 7 missing"))
+		b := NewNotFoundError("user 1337 not found", fmt.Errorf("row 9 missing"))
+
+		if got, want := Fingerprint(a), Fingerprint(b); got != want {
+			t.Fatalf("\n got:  %v\n want: %v", got, want)
+		}
+		if len(Fingerprint(a)) != 32 {
+			t.Fatalf("unexpected fingerprint %s", Fingerprint(a))
+		}
+	})
+
+	t.Run("should differ by name, status code and cause type", func(t *testing.T) {
+		fingerprints := map[string]string{}
+		for _, err := range []error{
+			NewNotFoundError("not found", nil),
+			NewGoneError("not found", nil),
+			NewHTTPError(404, "missing_error", "not found", nil),
+			NewNotFoundError("not found", New("cause")),
+			NewNotFoundError("not found", fmt.Errorf("cause")),
+			NewWithName("not_found_error", "not found"),
+		} {
+			fp := Fingerprint(err)
+			if other, ok := fingerprints[fp]; ok {
+				t.Fatalf("%v and %v have the same fingerprint", err, other)
+			}
+			fingerprints[fp] = err.Error()
+		}
+	})
+
+	t.Run("should ignore the wrapping messages of foreign errors", func(t *testing.T) {
+		cause := New("boom")
+		a := fmt.Errorf("calling 1: %w", cause)
+		b := fmt.Errorf("calling 2: %w", cause)
+
+		if Fingerprint(a) != Fingerprint(b) {
+			t.Fatalf("expected the same fingerprint")
+		}
+	})
+
+	t.Run("should hash the functions of the stack traces", func(t *testing.T) {
+		if Fingerprint(panicAt()) != Fingerprint(panicAt()) {
+			t.Fatalf("expected the same fingerprint")
+		}
+
+		if Fingerprint(panicAt()) == Fingerprint(Safe(func() error { panic("boom") })) {
+			t.Fatalf("expected the stack traces to differ")
+		}
+	})
+
+	t.Run("should ignore the operations", func(t *testing.T) {
+		err := New("boom")
+
+		if Fingerprint(Op("users.Get").Wrap(err)) != Fingerprint(err) {
+			t.Fatalf("expected the same fingerprint")
+		}
+	})
+
+	t.Run("should hash the causes of multi errors", func(t *testing.T) {
+		a := &MultiError{Name: "multi_error", Causes: []Cause{{Label: "a", Err: New("boom")}}}
+		b := &MultiError{Name: "multi_error", Causes: []Cause{{Label: "a", Err: New("bang")}}}
+
+		if Fingerprint(a) == Fingerprint(b) {
+			t.Fatalf("expected the causes to differ")
+		}
+	})
+
+	t.Run("should return an empty fingerprint for nil", func(t *testing.T) {
+		if got := Fingerprint(nil); got != "" {
+			t.Fatalf("\n got:  %v\n want: %v", got, "")
+		}
+	})
+}
@@ -2,6 +2,8 @@ package errors
 
 import (
 	"encoding/json"
+	"encoding/xml"
+	stderrors "errors"
 	"fmt"
 
 	"github.com/theothertomelliott/acyclic"
@@ -18,16 +20,39 @@ type GenericError struct {
 
 	// Original is an optional original error
 	Original error `json:"original,omitempty"`
+
+	// Details is an optional list of typed error details
+	Details Details `json:"details,omitempty"`
+
+	// Source is the optional location the error was
+	// created at, see EnableSource
+	Source *Frame `json:"source,omitempty"`
+
+	// Stack is an optional stack trace of the error
+	Stack Stack `json:"stack,omitempty"`
+
+	// Ops is the trail of operations the error went through,
+	// from the outermost to the innermost one
+	Ops []string `json:"ops,omitempty"`
+
+	// retryable overrides the retryable classification
+	retryable *bool
+
+	// returns is the return trace of the error
+	returns []uintptr
+
+	// source is the error this error is a copy of
+	source error
 }
 
-// Error returns a string concatenation of the name, message
-// and original error.
+// Error returns a string concatenation of the operation
+// trail, name, message and original error.
 func (e GenericError) Error() string {
 	if e.Original == nil {
-		return fmt.Sprintf("%s: %s", e.Name, e.Message)
+		return fmt.Sprintf("%s%s: %s", opsPrefix(e.Ops), e.Name, e.Message)
 	}
 
-	return fmt.Sprintf("%s: %s (%s)", e.Name, e.Message, e.Original.Error())
+	return fmt.Sprintf("%s%s: %s (%s)", opsPrefix(e.Ops), e.Name, e.Message, e.Original.Error())
 }
 
 // JSON returns the JSON representation of the error
@@ -45,7 +70,7 @@ func (e GenericError) JSON() []byte {
 		))
 	}
 
-	b, err := json.Marshal(e)
+	b, err := json.Marshal(e.jsonValue())
 	if err != nil {
 		e.Original = New(fmt.Sprintf(
 			"GenericError.Original is not marshallable (%s), original: %s",
@@ -53,19 +78,94 @@ func (e GenericError) JSON() []byte {
 			e.Original.Error(),
 		))
 
-		b, _ = json.Marshal(e)
+		b, _ = json.Marshal(e.jsonValue())
 	}
 
 	return b
 }
 
+// jsonValue returns the value marshalled by JSON(), which
+// adds the return trace and the fingerprint of the error chain
+// to the properties.
+func (e GenericError) jsonValue() any {
+	type alias GenericError
+	return struct {
+		alias
+		ReturnTrace Stack  `json:"returnTrace,omitempty"`
+		Fingerprint string `json:"fingerprint,omitempty"`
+	}{alias: alias(e), ReturnTrace: ReturnTrace(&e), Fingerprint: jsonFingerprint(&e)}
+}
+
+// Format implements the fmt.Formatter interface. The %+v
+// verb prints the return trace after the error.
+func (e GenericError) Format(s fmt.State, verb rune) {
+	type goSyntax GenericError
+	formatError(s, verb, e, goSyntax(e))
+}
+
+// Is reports whether the error is a copy of target made to
+// record an operation or a return location.
+func (e GenericError) Is(target error) bool {
+	return e.source != nil && stderrors.Is(e.source, target)
+}
+
+// XML returns the XML representation of the error.
+//
+// Errors wrapped by the Original property are rendered
+// as nested elements.
+func (e GenericError) XML() []byte {
+	return marshalXMLError(&e)
+}
+
+// MarshalXML implements the xml.Marshaler interface.
+func (e GenericError) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	return enc.EncodeElement(newXMLError(&e, start.Name.Local, 0), start)
+}
+
+// Text returns the plain text representation of the error,
+// which is the same as the Error() method.
+func (e GenericError) Text() []byte {
+	return []byte(e.Error())
+}
+
+// UnmarshalJSON decodes the JSON representation returned
+// by the JSON() method. The original error is decoded as an
+// HTTPError or a GenericError depending on it's properties.
+func (e *GenericError) UnmarshalJSON(b []byte) error {
+	type alias GenericError
+	v := struct {
+		*alias
+		Original json.RawMessage `json:"original"`
+	}{alias: (*alias)(e)}
+
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+
+	orig, err := decodeError(v.Original)
+	if err != nil {
+		return err
+	}
+	e.Original = orig
+
+	return nil
+}
+
+// WithDetails appends the given details to the error
+// and returns it.
+func (e *GenericError) WithDetails(details ...Detail) *GenericError {
+	e.Details = append(e.Details, details...)
+	return e
+}
+
 // Wrap wraps an error in a GenericError. It sets the name
 // of the error to "error", the message to the original
 // error.Error() value and the original property to the
 // original error.
 //
 // If the given error is an Error, it will
-// be returned as is.
+// be returned as is, or as a copy recording the return
+// location when the return trace is enabled.
 //
 // You can assert that the returned error is of type
 // Error by doing:
@@ -96,6 +196,30 @@ func Wrap(err error) error {
 		return nil
 	}
 
+	pc := returnPC()
+	if _, ok := err.(Error); ok {
+		if pc != 0 {
+			err, _ = annotate(err, "", pc)
+		}
+		return err
+	}
+
+	countCreated("error", 0)
+	return &GenericError{
+		Name:     "error",
+		Message:  err.Error(),
+		Original: err,
+		returns:  appendPC(nil, pc),
+	}
+}
+
+// wrap is Wrap without the recording of the return trace,
+// used to render errors.
+func wrap(err error) error {
+	if err == nil {
+		return nil
+	}
+
 	if _, ok := err.(Error); ok {
 		return err
 	}
@@ -111,9 +235,11 @@ func Wrap(err error) error {
 // name set to "error", the message set to
 // the given message.
 func New(msg string) error {
+	countCreated("error", 0)
 	return &GenericError{
 		Name:    "error",
 		Message: msg,
+		Source:  captureSource(1),
 	}
 }
 
@@ -121,9 +247,11 @@ func New(msg string) error {
 // name set to the given name and the message set to
 // the given message.
 func NewWithName(name, msg string) error {
+	countCreated(name, 0)
 	return &GenericError{
 		Name:    name,
 		Message: msg,
+		Source:  captureSource(1),
 	}
 }
 
@@ -132,9 +260,12 @@ func NewWithName(name, msg string) error {
 // the given message and the original property set to the given
 // original error.
 func NewWithNameAndErr(name, msg string, orig error) error {
+	countCreated(name, 0)
 	return &GenericError{
 		Name:     name,
 		Message:  msg,
 		Original: orig,
+		Source:   captureSource(1),
+		returns:  appendPC(nil, returnPC()),
 	}
 }
@@ -0,0 +1,107 @@
+package errors
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+)
+
+// GraphQLLocation is the location in the GraphQL document
+// associated with an error.
+type GraphQLLocation struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// GraphQLError is an entry of the "errors" array of a
+// GraphQL response.
+//
+// See https://spec.graphql.org/October2021/#sec-Errors
+type GraphQLError struct {
+	Message    string            `json:"message"`
+	Locations  []GraphQLLocation `json:"locations,omitempty"`
+	Path       []any             `json:"path,omitempty"`
+	Extensions map[string]any    `json:"extensions,omitempty"`
+}
+
+// Error returns the message of the GraphQL error.
+func (e GraphQLError) Error() string {
+	return e.Message
+}
+
+// WithLocations returns a copy of the GraphQL error with
+// the given locations appended.
+func (e GraphQLError) WithLocations(locations ...GraphQLLocation) GraphQLError {
+	e.Locations = append(append([]GraphQLLocation(nil), e.Locations...), locations...)
+	return e
+}
+
+// NewGraphQLError converts an error to a GraphQL error.
+//
+// The path is the path of the response field that failed,
+// made of field names (string) and list indices (int). The
+// error name is set to the "code" extension, the HTTPError
+// status code to the "status" extension and the error details
+// to the "details" extension. A nil error is converted as an
+// internal server error.
+func NewGraphQLError(err error, path ...any) GraphQLError {
+	if err == nil {
+		httpErr := toHTTPError(nil)
+		httpErr.Message = http.StatusText(httpErr.StatusCode)
+		err = httpErr
+	}
+
+	if gqlErr, ok := err.(GraphQLError); ok {
+		if len(path) > 0 {
+			gqlErr.Path = path
+		}
+		return gqlErr
+	}
+
+	httpErr := toHTTPError(err)
+	extensions := map[string]any{
+		"code": httpErr.Name,
+	}
+
+	if stderrors.As(err, new(*HTTPError)) {
+		extensions["status"] = httpErr.StatusCode
+	}
+
+	if len(httpErr.Details) > 0 {
+		b, _ := json.Marshal(httpErr.Details)
+		extensions["details"] = json.RawMessage(b)
+	}
+
+	message := httpErr.Message
+	if message == "" {
+		message = err.Error()
+	}
+
+	return GraphQLError{
+		Message:    message,
+		Path:       path,
+		Extensions: extensions,
+	}
+}
+
+// NewGraphQLErrors converts the given errors to the "errors"
+// array of a GraphQL response. Nil errors are skipped and
+// errors joining multiple errors (i.e. Unwrap() []error) are
+// flattened.
+func NewGraphQLErrors(errs ...error) []GraphQLError {
+	gqlErrs := []GraphQLError{}
+	for _, err := range errs {
+		switch e := err.(type) {
+		case nil:
+			continue
+		case GraphQLError:
+			gqlErrs = append(gqlErrs, e)
+		case interface{ Unwrap() []error }:
+			gqlErrs = append(gqlErrs, NewGraphQLErrors(e.Unwrap()...)...)
+		default:
+			gqlErrs = append(gqlErrs, NewGraphQLError(err))
+		}
+	}
+
+	return gqlErrs
+}
@@ -0,0 +1,77 @@
+package errors
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestNewGraphQLError(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should convert an http error", func(t *testing.T) {
+		err := NewNotFoundError("user not found", nil).(*HTTPError).
+			WithDetails(ErrorInfo{Reason: "USER_NOT_FOUND"})
+
+		want := `{"message":"user not found","path":["user",0,"name"],"extensions":{"code":"not_found_error","details":[{"@type":"type.googleapis.com/google.rpc.ErrorInfo","reason":"USER_NOT_FOUND"}],"status":404}}`
+		b, _ := json.Marshal(NewGraphQLError(err, "user", 0, "name"))
+
+		if got := string(b); got != want {
+			t.Fatalf("\n got:  %v\n want: %v", got, want)
+		}
+	})
+
+	t.Run("should convert a generic error without status", func(t *testing.T) {
+		want := `{"message":"boom","extensions":{"code":"my_error"}}`
+		b, _ := json.Marshal(NewGraphQLError(NewWithName("my_error", "boom")))
+
+		if got := string(b); got != want {
+			t.Fatalf("\n got:  %v\n want: %v", got, want)
+		}
+	})
+
+	t.Run("should convert a nil error to an internal error", func(t *testing.T) {
+		got := NewGraphQLError(nil, "user")
+		want := `{"message":"Internal Server Error","path":["user"],"extensions":{"code":"internal_server_error","status":500}}`
+
+		if b, _ := json.Marshal(got); string(b) != want {
+			t.Fatalf("\n got:  %s\n want: %s", b, want)
+		}
+	})
+
+	t.Run("should keep graphql errors and set their path", func(t *testing.T) {
+		gqlErr := GraphQLError{Message: "boom"}.WithLocations(GraphQLLocation{Line: 1, Column: 2})
+		got := NewGraphQLError(gqlErr, "field")
+
+		if got.Message != "boom" || len(got.Locations) != 1 || got.Path[0] != "field" {
+			t.Fatalf("unexpected graphql error: %#v", got)
+		}
+	})
+}
+
+func TestNewGraphQLErrors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should aggregate and flatten errors", func(t *testing.T) {
+		joined := errors.Join(NewBadRequestError("invalid", nil), errors.New("plain"))
+		got := NewGraphQLErrors(New("first"), nil, joined)
+
+		if len(got) != 3 {
+			t.Fatalf("expected 3 errors, got %d", len(got))
+		}
+
+		want := []string{"first", "invalid", "plain"}
+		for i, gqlErr := range got {
+			if gqlErr.Message != want[i] {
+				t.Fatalf("\n got:  %v\n want: %v", gqlErr.Message, want[i])
+			}
+		}
+	})
+
+	t.Run("should return an empty array when there are no errors", func(t *testing.T) {
+		b, _ := json.Marshal(NewGraphQLErrors())
+		if string(b) != "[]" {
+			t.Fatalf("\n got:  %v\n want: %v", string(b), "[]")
+		}
+	})
+}
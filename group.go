@@ -0,0 +1,138 @@
+package errors
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"sync"
+)
+
+// GroupMode defines how a Group reacts to failures.
+type GroupMode int
+
+const (
+	// GroupFailFast cancels the group context on the first
+	// failure. Cancellation errors returned afterwards by the
+	// other goroutines are not collected.
+	GroupFailFast GroupMode = iota
+
+	// GroupCollectAll lets every goroutine run to completion
+	// and collects all of their failures.
+	GroupCollectAll
+)
+
+// Group runs labelled goroutines and collects their errors.
+// Panics are recovered and converted to GenericError values
+// named "panic" with the stack trace at the panic site.
+//
+// A Group must be created with NewGroup and must not be
+// reused after Wait returns.
+type Group struct {
+	mode   GroupMode
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	wg     sync.WaitGroup
+	sem    chan struct{}
+
+	mu       sync.Mutex
+	total    int
+	causes   []Cause
+	canceled bool
+}
+
+// NewGroup creates a new Group and the context passed to
+// it's goroutines, which is canceled on the first failure in
+// GroupFailFast mode or once Wait returns.
+func NewGroup(ctx context.Context, mode GroupMode) (*Group, context.Context) {
+	ctx, cancel := context.WithCancelCause(ctx)
+
+	return &Group{mode: mode, ctx: ctx, cancel: cancel}, ctx
+}
+
+// SetLimit limits the number of goroutines running at the
+// same time. A zero or negative value removes the limit.
+// It must be called before the first call to Go.
+func (g *Group) SetLimit(n int) {
+	if n <= 0 {
+		g.sem = nil
+		return
+	}
+
+	g.sem = make(chan struct{}, n)
+}
+
+// Go runs fn in a new goroutine, the label identifies it in
+// the returned error. When the limit of running goroutines
+// is reached, Go blocks until one of them returns.
+func (g *Group) Go(label string, fn func(ctx context.Context) error) {
+	if g.sem != nil {
+		g.sem <- struct{}{}
+	}
+
+	g.mu.Lock()
+	g.total++
+	g.mu.Unlock()
+
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		if g.sem != nil {
+			defer func() { <-g.sem }()
+		}
+
+		g.record(label, run(g.ctx, fn))
+	}()
+}
+
+// run calls fn and converts a panic into an error.
+func run(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	defer Recover(&err)
+
+	return fn(ctx)
+}
+
+func (g *Group) record(label string, err error) {
+	if err == nil {
+		return
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.mode == GroupFailFast {
+		if g.canceled && stderrors.Is(err, context.Canceled) {
+			return
+		}
+
+		if !g.canceled {
+			g.canceled = true
+			g.cancel(err)
+		}
+	}
+
+	g.causes = append(g.causes, Cause{Label: label, Err: err})
+}
+
+// Wait waits for every goroutine to return and cancels the
+// group context.
+//
+// It returns nil when every goroutine succeeded, otherwise a
+// MultiError named "group_error" with every failure labelled
+// with it's goroutine label.
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	g.cancel(nil)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if len(g.causes) == 0 {
+		return nil
+	}
+
+	return &MultiError{
+		Name:    "group_error",
+		Message: fmt.Sprintf("%d of %d goroutines failed", len(g.causes), g.total),
+		Causes:  append([]Cause(nil), g.causes...),
+	}
+}
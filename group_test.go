@@ -0,0 +1,110 @@
+package errors
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGroup(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should return nil when every goroutine succeeds", func(t *testing.T) {
+		g, _ := NewGroup(context.Background(), GroupCollectAll)
+		for i := 0; i < 5; i++ {
+			g.Go("ok", func(ctx context.Context) error { return nil })
+		}
+
+		if err := g.Wait(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("should collect every failure with its label", func(t *testing.T) {
+		g, _ := NewGroup(context.Background(), GroupCollectAll)
+		g.Go("users", func(ctx context.Context) error { return NewNotFoundError("user not found", nil) })
+		g.Go("orders", func(ctx context.Context) error { return errors.New("timeout") })
+		g.Go("items", func(ctx context.Context) error { return nil })
+
+		err, ok := g.Wait().(*MultiError)
+		if !ok {
+			t.Fatalf("expected error to be of type MultiError, got %T", err)
+		}
+
+		if err.Name != "group_error" || err.Message != "2 of 3 goroutines failed" || len(err.Causes) != 2 {
+			t.Fatalf("unexpected group error: %s", err.JSON())
+		}
+
+		json := string(err.JSON())
+		for _, want := range []string{`"label":"users"`, `"label":"orders"`} {
+			if !strings.Contains(json, want) {
+				t.Fatalf("expected %s in %s", want, json)
+			}
+		}
+	})
+
+	t.Run("should cancel the context on the first failure", func(t *testing.T) {
+		g, ctx := NewGroup(context.Background(), GroupFailFast)
+		g.Go("fails", func(ctx context.Context) error { return NewBadGatewayError("upstream", nil) })
+		g.Go("waits", func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+
+		err := g.Wait().(*MultiError)
+		if len(err.Causes) != 1 || err.Causes[0].Label != "fails" {
+			t.Fatalf("expected only the first failure, got %s", err.JSON())
+		}
+
+		if !errors.Is(context.Cause(ctx), err.Causes[0].Err) {
+			t.Fatalf("expected the context cause to be the first failure, got %v", context.Cause(ctx))
+		}
+	})
+
+	t.Run("should convert panics to errors with a stack", func(t *testing.T) {
+		g, _ := NewGroup(context.Background(), GroupCollectAll)
+		g.Go("panics", func(ctx context.Context) error { panic("boom") })
+
+		err := g.Wait().(*MultiError)
+		panicErr, ok := err.Causes[0].Err.(*GenericError)
+		if !ok || panicErr.Name != "panic" || panicErr.Message != "boom" {
+			t.Fatalf("expected a panic error, got %v", err.Causes[0].Err)
+		}
+
+		if len(panicErr.Stack) == 0 || !strings.Contains(panicErr.Stack[0].Function, "TestGroup") {
+			t.Fatalf("expected the stack to start at the panic site, got:\n%s", panicErr.Stack)
+		}
+	})
+
+	t.Run("should limit the running goroutines", func(t *testing.T) {
+		g, _ := NewGroup(context.Background(), GroupCollectAll)
+		g.SetLimit(2)
+
+		var running, max int32
+		for i := 0; i < 10; i++ {
+			g.Go("limited", func(ctx context.Context) error {
+				n := atomic.AddInt32(&running, 1)
+				for {
+					m := atomic.LoadInt32(&max)
+					if n <= m || atomic.CompareAndSwapInt32(&max, m, n) {
+						break
+					}
+				}
+				time.Sleep(time.Millisecond)
+				atomic.AddInt32(&running, -1)
+				return nil
+			})
+		}
+
+		if err := g.Wait(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if max > 2 {
+			t.Fatalf("expected at most 2 running goroutines, got %d", max)
+		}
+	})
+}
@@ -0,0 +1,146 @@
+package errors
+
+import (
+	"bytes"
+	"html/template"
+	"net/http"
+)
+
+// MediaTypeHTML is the media type of the HTML error pages
+// written by WriteError.
+const MediaTypeHTML = "text/html"
+
+// HTMLPage is the data passed to the templates of an
+// HTMLRenderer.
+type HTMLPage struct {
+	StatusCode int
+	StatusText string
+	Name       string
+	Message    string
+
+	// Causes is the chain of errors wrapped by the error,
+	// it is empty when the renderer is in production mode.
+	Causes []HTMLCause
+}
+
+// HTMLCause is an error of the cause chain of an HTMLPage.
+type HTMLCause struct {
+	Name    string
+	Message string
+}
+
+// HTMLRenderer renders errors as HTML pages for browser
+// clients using a html/template per status class.
+type HTMLRenderer struct {
+	// Templates are the templates used to render the
+	// pages indexed by status class (i.e. 4 for 4xx and
+	// 5 for 5xx status codes). Classes without a template
+	// use the default template of the class.
+	Templates map[int]*template.Template
+
+	// Production hides the cause chain of the errors
+	// from the rendered pages.
+	Production bool
+}
+
+// DefaultHTMLRenderer is the HTMLRenderer used by WriteError.
+var DefaultHTMLRenderer = &HTMLRenderer{}
+
+const htmlLayout = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.StatusCode}} {{.StatusText}}</title>
+<style>
+body{font-family:system-ui,sans-serif;max-width:40rem;margin:4rem auto;padding:0 1rem;color:#222}
+h1{font-size:1.5rem}code{background:#f2f2f2;padding:.1rem .3rem}
+ol{padding-left:1.2rem}
+</style>
+</head>
+<body>
+<h1>{{.StatusCode}} {{.StatusText}}</h1>
+<p>{{template "hint" .}}</p>
+<p>{{.Message}}</p>
+<p><code>{{.Name}}</code></p>
+{{- if .Causes}}
+<h2>Caused by</h2>
+<ol>
+{{- range .Causes}}
+<li><code>{{.Name}}</code>: {{.Message}}</li>
+{{- end}}
+</ol>
+{{- end}}
+</body>
+</html>
+`
+
+// defaultHTMLTemplates are the default templates by status class.
+var defaultHTMLTemplates = map[int]*template.Template{
+	4: newHTMLTemplate("4xx", `The request could not be completed.`),
+	5: newHTMLTemplate("5xx", `Something went wrong on our side, please try again later.`),
+}
+
+// newHTMLTemplate returns the default layout named name
+// with the given hint.
+func newHTMLTemplate(name, hint string) *template.Template {
+	tmpl := template.Must(template.New(name).Parse(htmlLayout))
+	template.Must(tmpl.New("hint").Parse(hint))
+
+	return tmpl
+}
+
+// Render renders the given error as an HTML page. Message and
+// causes are escaped by html/template. If the template fails
+// to execute, a minimal page is rendered instead.
+func (h *HTMLRenderer) Render(err error) []byte {
+	page := h.page(err)
+
+	class := page.StatusCode / 100
+	tmpl, ok := h.Templates[class]
+	if !ok {
+		tmpl, ok = defaultHTMLTemplates[class]
+	}
+	if !ok {
+		tmpl = defaultHTMLTemplates[5]
+	}
+
+	var buf bytes.Buffer
+	if execErr := tmpl.ExecuteTemplate(&buf, tmpl.Name(), page); execErr != nil {
+		buf.Reset()
+		page.Causes = nil
+		_ = defaultHTMLTemplates[5].ExecuteTemplate(&buf, "5xx", page)
+	}
+
+	return buf.Bytes()
+}
+
+func (h *HTMLRenderer) page(err error) HTMLPage {
+	httpErr := toHTTPError(err)
+	page := HTMLPage{
+		StatusCode: httpErr.StatusCode,
+		StatusText: http.StatusText(httpErr.StatusCode),
+		Name:       httpErr.Name,
+		Message:    httpErr.Message,
+	}
+
+	if h.Production {
+		return page
+	}
+
+	cause := unwrapOnce(httpErr)
+	for depth := 0; cause != nil && depth < maxErrorDepth; depth++ {
+		htmlCause := HTMLCause{Name: "error", Message: cause.Error()}
+		switch e := cause.(type) {
+		case *HTTPError:
+			htmlCause = HTMLCause{Name: e.Name, Message: e.Message}
+		case *GenericError:
+			htmlCause = HTMLCause{Name: e.Name, Message: e.Message}
+		}
+
+		page.Causes = append(page.Causes, htmlCause)
+		cause = unwrapOnce(cause)
+	}
+
+	return page
+}
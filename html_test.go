@@ -0,0 +1,89 @@
+package errors
+
+import (
+	"errors"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHTMLRenderer_Render(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should escape the message and show the causes", func(t *testing.T) {
+		err := NewBadRequestError("<script>alert(1)</script>", New("inner <b>"))
+		got := string((&HTMLRenderer{}).Render(err))
+
+		for _, want := range []string{
+			"<title>400 Bad Request</title>",
+			"&lt;script&gt;alert(1)&lt;/script&gt;",
+			"The request could not be completed.",
+			"<li><code>error</code>: inner &lt;b&gt;</li>",
+		} {
+			if !strings.Contains(got, want) {
+				t.Fatalf("expected page to contain %q, got:\n%s", want, got)
+			}
+		}
+
+		if strings.Contains(got, "<script>") {
+			t.Fatalf("expected message to be escaped, got:\n%s", got)
+		}
+	})
+
+	t.Run("should hide the causes in production", func(t *testing.T) {
+		err := NewInternalServerError("boom", errors.New("secret dsn"))
+		got := string((&HTMLRenderer{Production: true}).Render(err))
+
+		if strings.Contains(got, "secret dsn") || strings.Contains(got, "Caused by") {
+			t.Fatalf("expected causes to be hidden, got:\n%s", got)
+		}
+
+		if !strings.Contains(got, "Something went wrong on our side") {
+			t.Fatalf("expected the 5xx page, got:\n%s", got)
+		}
+	})
+
+	t.Run("should use the template of the status class", func(t *testing.T) {
+		r := &HTMLRenderer{Templates: map[int]*template.Template{
+			4: template.Must(template.New("custom").Parse(`<p>{{.StatusCode}}: {{.Message}}</p>`)),
+		}}
+
+		want := `<p>404: a &amp; b</p>`
+		if got := string(r.Render(NewNotFoundError("a & b", nil))); got != want {
+			t.Fatalf("\n got:  %v\n want: %v", got, want)
+		}
+	})
+
+	t.Run("should fallback to the default page when the template fails", func(t *testing.T) {
+		r := &HTMLRenderer{Templates: map[int]*template.Template{
+			4: template.Must(template.New("broken").Parse(`{{.Missing}}`)),
+		}}
+
+		got := string(r.Render(NewNotFoundError("missing", nil)))
+		if !strings.Contains(got, "<title>404 Not Found</title>") {
+			t.Fatalf("expected the default page, got:\n%s", got)
+		}
+	})
+}
+
+func TestWriteError_HTML(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should write an html page to browsers", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+		rec := httptest.NewRecorder()
+
+		WriteError(rec, r, NewForbiddenError("forbidden", nil))
+
+		if got := rec.Header().Get("Content-Type"); got != "text/html; charset=utf-8" {
+			t.Fatalf("\n got:  %v\n want: %v", got, "text/html; charset=utf-8")
+		}
+
+		if !strings.Contains(rec.Body.String(), "<title>403 Forbidden</title>") {
+			t.Fatalf("unexpected body: %s", rec.Body.String())
+		}
+	})
+}
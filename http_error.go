@@ -2,33 +2,62 @@ package errors
 
 import (
 	"encoding/json"
+	"encoding/xml"
+	stderrors "errors"
 	"fmt"
 	"net/http"
+	"strings"
+	"unicode"
 
 	"github.com/theothertomelliott/acyclic"
 )
 
+//go:generate go run ./internal/cmd/genstatus -o http_status.go -t http_status_test.go
+
 var _ Error = &HTTPError{}
 
 // HTTPError is a struct that represents an HTTP error
 // and it implements the Error interface.
 type HTTPError struct {
-	StatusCode int    `json:"statusCode"`
-	Name       string `json:"name"`
-	Message    string `json:"message"`
-	Err        error  `json:"error"`
+	StatusCode int     `json:"statusCode"`
+	Name       string  `json:"name"`
+	Message    string  `json:"message"`
+	Err        error   `json:"error"`
+	Details    Details `json:"details,omitempty"`
+
+	// Source is the optional location the error was
+	// created at, see EnableSource.
+	Source *Frame `json:"source,omitempty"`
+
+	// Ops is the trail of operations the error went through,
+	// from the outermost to the innermost one.
+	Ops []string `json:"ops,omitempty"`
+
+	// Header are the response headers written along
+	// with the error (e.g. Retry-After).
+	Header http.Header `json:"-"`
+
+	// retryable overrides the retryable classification
+	retryable *bool
+
+	// returns is the return trace of the error
+	returns []uintptr
+
+	// source is the error this error is a copy of
+	source error
 }
 
-// Error returns a string concatenation of the name
-// and message. If th Err property is not nil, it
+// Error returns a string concatenation of the operation
+// trail, name and message. If th Err property is not nil, it
 // will be returned in the message.
 func (e *HTTPError) Error() string {
 	if e.Err == nil {
-		return fmt.Sprintf("%s: %s", e.Name, e.Message)
+		return fmt.Sprintf("%s%s: %s", opsPrefix(e.Ops), e.Name, e.Message)
 	}
 
 	return fmt.Sprintf(
-		"%s: %s (%s)",
+		"%s%s: %s (%s)",
+		opsPrefix(e.Ops),
 		e.Name,
 		e.Message,
 		e.Err.Error(),
@@ -49,7 +78,7 @@ func (e *HTTPError) Error() string {
 func (e HTTPError) JSON() []byte {
 	if e.Err != nil {
 		if _, ok := e.Err.(Error); !ok {
-			e.Err = Wrap(e.Err)
+			e.Err = wrap(e.Err)
 		}
 	}
 
@@ -61,7 +90,7 @@ func (e HTTPError) JSON() []byte {
 		))
 	}
 
-	b, err := json.Marshal(e)
+	b, err := json.Marshal(e.jsonValue())
 	if err != nil {
 		e.Err = New(fmt.Sprintf(
 			"HTTPError.Err is not marshallable (%s), original: %s",
@@ -69,68 +98,133 @@ func (e HTTPError) JSON() []byte {
 			e.Err.Error(),
 		))
 
-		b, _ = json.Marshal(e)
+		b, _ = json.Marshal(e.jsonValue())
 	}
 
 	return b
 }
 
-// NewHTTPError creates a new HTTPError.
-func NewHTTPError(statusCode int, name, message string, err error) error {
-	return &HTTPError{
-		StatusCode: statusCode,
-		Name:       name,
-		Message:    message,
-		Err:        err,
-	}
+// jsonValue returns the value marshalled by JSON(), which
+// adds the return trace and the fingerprint of the error chain
+// to the properties.
+func (e HTTPError) jsonValue() any {
+	type alias HTTPError
+	return struct {
+		alias
+		ReturnTrace Stack  `json:"returnTrace,omitempty"`
+		Fingerprint string `json:"fingerprint,omitempty"`
+	}{alias: alias(e), ReturnTrace: ReturnTrace(&e), Fingerprint: jsonFingerprint(&e)}
 }
 
-// NewBadRequestError creates a new HTTPError with a 400 status code.
-func NewBadRequestError(message string, err error) error {
-	return NewHTTPError(http.StatusBadRequest, "bad_request_error", message, err)
+// Format implements the fmt.Formatter interface. The %+v
+// verb prints the return trace after the error.
+func (e *HTTPError) Format(s fmt.State, verb rune) {
+	type goSyntax HTTPError
+	formatError(s, verb, e, (*goSyntax)(e))
 }
 
-// NewNotFoundError creates a new HTTPError with a 404 status code.
-func NewNotFoundError(message string, err error) error {
-	return NewHTTPError(http.StatusNotFound, "not_found_error", message, err)
+// Is reports whether the error is a copy of target made to
+// record an operation or a return location.
+func (e *HTTPError) Is(target error) bool {
+	return e.source != nil && stderrors.Is(e.source, target)
 }
 
-// NewInternalServerError creates a new HTTPError with a 500 status code.
-func NewInternalServerError(message string, err error) error {
-	return NewHTTPError(http.StatusInternalServerError, "internal_server_error", message, err)
+// XML returns the XML representation of the error.
+//
+// Errors wrapped by the Err property are rendered
+// as nested elements.
+func (e HTTPError) XML() []byte {
+	return marshalXMLError(&e)
 }
 
-// NewUnauthorizedError creates a new HTTPError with a 401 status code.
-func NewUnauthorizedError(message string, err error) error {
-	return NewHTTPError(http.StatusUnauthorized, "unauthorized_error", message, err)
+// MarshalXML implements the xml.Marshaler interface.
+func (e HTTPError) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	return enc.EncodeElement(newXMLError(&e, start.Name.Local, 0), start)
 }
 
-// NewForbiddenError creates a new HTTPError with a 403 status code.
-func NewForbiddenError(message string, err error) error {
-	return NewHTTPError(http.StatusForbidden, "forbidden_error", message, err)
+// Text returns the plain text representation of the error,
+// which is the status code followed by the Error() method.
+func (e HTTPError) Text() []byte {
+	return []byte(fmt.Sprintf("%d %s", e.StatusCode, e.Error()))
 }
 
-// NewConflictError creates a new HTTPError with a 409 status code.
-func NewConflictError(message string, err error) error {
-	return NewHTTPError(http.StatusConflict, "conflict_error", message, err)
+// UnmarshalJSON decodes the JSON representation returned
+// by the JSON() method. The Err property is decoded as an
+// HTTPError or a GenericError depending on it's properties.
+func (e *HTTPError) UnmarshalJSON(b []byte) error {
+	type alias HTTPError
+	v := struct {
+		*alias
+		Err json.RawMessage `json:"error"`
+	}{alias: (*alias)(e)}
+
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+
+	inner, err := decodeError(v.Err)
+	if err != nil {
+		return err
+	}
+	e.Err = inner
+
+	return nil
 }
 
-// NewTooManyRequestsError creates a new HTTPError with a 429 status code.
-func NewTooManyRequestsError(message string, err error) error {
-	return NewHTTPError(http.StatusTooManyRequests, "too_many_requests_error", message, err)
+// WithDetails appends the given details to the error
+// and returns it.
+func (e *HTTPError) WithDetails(details ...Detail) *HTTPError {
+	e.Details = append(e.Details, details...)
+	return e
 }
 
-// NewBadGatewayError creates a new HTTPError with a 502 status code.
-func NewBadGatewayError(message string, err error) error {
-	return NewHTTPError(http.StatusBadGateway, "bad_gateway_error", message, err)
+// statusName returns the name used by the constructors of
+// this package for the given status code. Status codes without
+// a constructor get a name derived from their status text.
+func statusName(statusCode int) string {
+	if name, ok := statusNames[statusCode]; ok {
+		return name
+	}
+
+	text := http.StatusText(statusCode)
+	if text == "" {
+		return "http_error"
+	}
+
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	return strings.Join(words, "_") + "_error"
 }
 
-// NewServiceUnavailableError creates a new HTTPError with a 503 status code.
-func NewServiceUnavailableError(message string, err error) error {
-	return NewHTTPError(http.StatusServiceUnavailable, "service_unavailable_error", message, err)
+// ValidStatusCode reports whether the given status code
+// is in the 100-599 range.
+func ValidStatusCode(statusCode int) bool {
+	return statusCode >= 100 && statusCode <= 599
 }
 
-// NewGatewayTimeoutError creates a new HTTPError with a 504 status code.
-func NewGatewayTimeoutError(message string, err error) error {
-	return NewHTTPError(http.StatusGatewayTimeout, "gateway_timeout_error", message, err)
+// NewHTTPError creates a new HTTPError.
+//
+// Status codes outside of the 100-599 range are normalised
+// to 500.
+func NewHTTPError(statusCode int, name, message string, err error) error {
+	return newHTTPError(statusCode, name, message, err)
+}
+
+// newHTTPError creates a new HTTPError recording the location
+// of the caller of the exported constructor calling it.
+func newHTTPError(statusCode int, name, message string, err error) *HTTPError {
+	if !ValidStatusCode(statusCode) {
+		statusCode = http.StatusInternalServerError
+	}
+
+	countCreated(name, statusCode)
+	return &HTTPError{
+		StatusCode: statusCode,
+		Name:       name,
+		Message:    message,
+		Err:        err,
+		Source:     captureSource(2),
+	}
 }
@@ -337,3 +337,43 @@ func TestNewGatewayTimeoutError(t *testing.T) {
 		}
 	})
 }
+
+func TestNewHTTPError(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should normalise invalid status codes to 500", func(t *testing.T) {
+		for _, statusCode := range []int{-1, 0, 99, 600, rand.Int() + 600} {
+			got := NewHTTPError(statusCode, "name", "message", nil).(*HTTPError)
+			if got.StatusCode != http.StatusInternalServerError {
+				t.Fatalf("NewHTTPError(%d) status = %d, want %d", statusCode, got.StatusCode, http.StatusInternalServerError)
+			}
+		}
+	})
+
+	t.Run("should keep valid status codes", func(t *testing.T) {
+		for _, statusCode := range []int{100, 299, 418, 599} {
+			got := NewHTTPError(statusCode, "name", "message", nil).(*HTTPError)
+			if got.StatusCode != statusCode {
+				t.Fatalf("NewHTTPError(%d) status = %d, want %d", statusCode, got.StatusCode, statusCode)
+			}
+		}
+	})
+}
+
+func TestStatusName(t *testing.T) {
+	t.Parallel()
+
+	tests := map[int]string{
+		http.StatusMethodNotAllowed:    "method_not_allowed_error",
+		http.StatusInternalServerError: "internal_server_error",
+		http.StatusTeapot:              "i_m_a_teapot_error",
+		http.StatusCreated:             "created_error",
+		999:                            "http_error",
+	}
+
+	for statusCode, want := range tests {
+		if got := statusName(statusCode); got != want {
+			t.Errorf("statusName(%d) = %q, want %q", statusCode, got, want)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package errors
+
+import (
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WithHeader adds the given response header to the error
+// and returns it.
+func (e *HTTPError) WithHeader(key, value string) *HTTPError {
+	if e.Header == nil {
+		e.Header = http.Header{}
+	}
+
+	e.Header.Add(key, value)
+	return e
+}
+
+// WithRetryAfter sets the Retry-After response header to
+// the given duration, rounded up to the next second, and
+// returns the error.
+func (e *HTTPError) WithRetryAfter(d time.Duration) *HTTPError {
+	if e.Header == nil {
+		e.Header = http.Header{}
+	}
+
+	secs := int64(math.Ceil(d.Seconds()))
+	if secs < 0 {
+		secs = 0
+	}
+
+	e.Header.Set("Retry-After", strconv.FormatInt(secs, 10))
+	return e
+}
+
+// RetryAfter returns the duration of the Retry-After response
+// header of the error. The header can either be a number of
+// seconds or an HTTP date, the later is returned relative to
+// the current time.
+func (e *HTTPError) RetryAfter() (time.Duration, bool) {
+	return parseRetryAfter(e.Header.Get("Retry-After"), time.Now())
+}
+
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+
+	if secs, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+
+		return time.Duration(secs) * time.Second, true
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+
+	if d := date.Sub(now); d > 0 {
+		return d, true
+	}
+
+	return 0, true
+}
+
+// WithAuthChallenge adds a WWW-Authenticate response header
+// with the given authentication scheme and parameters (e.g.
+// realm) and returns the error. Parameters are written in
+// alphabetical order.
+func (e *HTTPError) WithAuthChallenge(scheme string, params map[string]string) *HTTPError {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	challenge := scheme
+	for i, k := range keys {
+		if i == 0 {
+			challenge += " "
+		} else {
+			challenge += ", "
+		}
+
+		challenge += k + "=" + quoteHeaderValue(params[k])
+	}
+
+	return e.WithHeader("WWW-Authenticate", challenge)
+}
+
+// WithAllow sets the Allow response header to the given
+// methods and returns the error.
+func (e *HTTPError) WithAllow(methods ...string) *HTTPError {
+	if e.Header == nil {
+		e.Header = http.Header{}
+	}
+
+	e.Header.Set("Allow", strings.Join(methods, ", "))
+	return e
+}
+
+func quoteHeaderValue(v string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(v) + `"`
+}
+
+// writeHeader copies the response headers of the HTTPError
+// wrapped by err to w.
+func writeHeader(w http.ResponseWriter, err error) {
+	httpErr := toHTTPError(err)
+	for k, values := range httpErr.Header {
+		for _, v := range values {
+			w.Header().Add(k, v)
+		}
+	}
+}
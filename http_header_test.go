@@ -0,0 +1,102 @@
+package errors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHTTPError_WithRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should round up to the next second", func(t *testing.T) {
+		err := NewServiceUnavailableError("unavailable", nil).(*HTTPError).WithRetryAfter(1500 * time.Millisecond)
+
+		if got := err.Header.Get("Retry-After"); got != "2" {
+			t.Fatalf("\n got:  %v\n want: %v", got, "2")
+		}
+
+		if got, ok := err.RetryAfter(); !ok || got != 2*time.Second {
+			t.Fatalf("\n got:  %v\n want: %v", got, 2*time.Second)
+		}
+	})
+
+	t.Run("should not be included in the JSON representation", func(t *testing.T) {
+		err := NewTooManyRequestsError("slow down", nil).(*HTTPError)
+		want := string(err.JSON())
+
+		if got := string(err.WithRetryAfter(time.Second).JSON()); got != want {
+			t.Fatalf("\n got:  %v\n want: %v", got, want)
+		}
+	})
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"", 0, false},
+		{"120", 2 * time.Minute, true},
+		{"-1", 0, false},
+		{"soon", 0, false},
+		{"Wed, 01 Jan 2025 00:00:30 GMT", 30 * time.Second, true},
+		{"Tue, 31 Dec 2024 23:59:00 GMT", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseRetryAfter(tt.value, now)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseRetryAfter(%q) = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestHTTPError_WithAuthChallenge(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should write the scheme and quoted params", func(t *testing.T) {
+		err := NewUnauthorizedError("unauthorized", nil).(*HTTPError).
+			WithAuthChallenge("Bearer", map[string]string{"realm": "api", "error": `invalid "token"`}).
+			WithAuthChallenge("Basic", nil)
+
+		want := []string{`Bearer error="invalid \"token\"", realm="api"`, "Basic"}
+		got := err.Header.Values("WWW-Authenticate")
+
+		if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+			t.Fatalf("\n got:  %q\n want: %q", got, want)
+		}
+	})
+}
+
+func TestHTTPError_WithAllow(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should set the allowed methods", func(t *testing.T) {
+		err := NewMethodNotAllowedError("method not allowed", nil).(*HTTPError).WithAllow(http.MethodGet, http.MethodHead)
+
+		if got := err.Header.Get("Allow"); got != "GET, HEAD" {
+			t.Fatalf("\n got:  %v\n want: %v", got, "GET, HEAD")
+		}
+	})
+}
+
+func TestWriteError_Header(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should write the error headers", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		err := NewMethodNotAllowedError("method not allowed", nil).(*HTTPError).WithAllow(http.MethodGet)
+
+		WriteError(rec, httptest.NewRequest(http.MethodPost, "/", nil), err)
+
+		if got := rec.Header().Get("Allow"); got != "GET" {
+			t.Fatalf("\n got:  %v\n want: %v", got, "GET")
+		}
+	})
+}
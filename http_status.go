@@ -0,0 +1,244 @@
+// Code generated by internal/cmd/genstatus. DO NOT EDIT.
+
+package errors
+
+import "net/http"
+
+// statusNames are the names of the errors created by the
+// constructors of every registered 4xx and 5xx status code.
+var statusNames = map[int]string{
+	http.StatusBadRequest:                    "bad_request_error",
+	http.StatusUnauthorized:                  "unauthorized_error",
+	http.StatusPaymentRequired:               "payment_required_error",
+	http.StatusForbidden:                     "forbidden_error",
+	http.StatusNotFound:                      "not_found_error",
+	http.StatusMethodNotAllowed:              "method_not_allowed_error",
+	http.StatusNotAcceptable:                 "not_acceptable_error",
+	http.StatusProxyAuthRequired:             "proxy_auth_required_error",
+	http.StatusRequestTimeout:                "request_timeout_error",
+	http.StatusConflict:                      "conflict_error",
+	http.StatusGone:                          "gone_error",
+	http.StatusLengthRequired:                "length_required_error",
+	http.StatusPreconditionFailed:            "precondition_failed_error",
+	http.StatusRequestEntityTooLarge:         "request_entity_too_large_error",
+	http.StatusRequestURITooLong:             "request_uri_too_long_error",
+	http.StatusUnsupportedMediaType:          "unsupported_media_type_error",
+	http.StatusRequestedRangeNotSatisfiable:  "requested_range_not_satisfiable_error",
+	http.StatusExpectationFailed:             "expectation_failed_error",
+	http.StatusMisdirectedRequest:            "misdirected_request_error",
+	http.StatusUnprocessableEntity:           "unprocessable_entity_error",
+	http.StatusLocked:                        "locked_error",
+	http.StatusFailedDependency:              "failed_dependency_error",
+	http.StatusTooEarly:                      "too_early_error",
+	http.StatusUpgradeRequired:               "upgrade_required_error",
+	http.StatusPreconditionRequired:          "precondition_required_error",
+	http.StatusTooManyRequests:               "too_many_requests_error",
+	http.StatusRequestHeaderFieldsTooLarge:   "request_header_fields_too_large_error",
+	http.StatusUnavailableForLegalReasons:    "unavailable_for_legal_reasons_error",
+	http.StatusInternalServerError:           "internal_server_error",
+	http.StatusNotImplemented:                "not_implemented_error",
+	http.StatusBadGateway:                    "bad_gateway_error",
+	http.StatusServiceUnavailable:            "service_unavailable_error",
+	http.StatusGatewayTimeout:                "gateway_timeout_error",
+	http.StatusHTTPVersionNotSupported:       "http_version_not_supported_error",
+	http.StatusVariantAlsoNegotiates:         "variant_also_negotiates_error",
+	http.StatusInsufficientStorage:           "insufficient_storage_error",
+	http.StatusLoopDetected:                  "loop_detected_error",
+	http.StatusNotExtended:                   "not_extended_error",
+	http.StatusNetworkAuthenticationRequired: "network_authentication_required_error",
+}
+
+// NewBadRequestError creates a new HTTPError with a 400 status code.
+func NewBadRequestError(message string, err error) error {
+	return newHTTPError(http.StatusBadRequest, "bad_request_error", message, err)
+}
+
+// NewUnauthorizedError creates a new HTTPError with a 401 status code.
+func NewUnauthorizedError(message string, err error) error {
+	return newHTTPError(http.StatusUnauthorized, "unauthorized_error", message, err)
+}
+
+// NewPaymentRequiredError creates a new HTTPError with a 402 status code.
+func NewPaymentRequiredError(message string, err error) error {
+	return newHTTPError(http.StatusPaymentRequired, "payment_required_error", message, err)
+}
+
+// NewForbiddenError creates a new HTTPError with a 403 status code.
+func NewForbiddenError(message string, err error) error {
+	return newHTTPError(http.StatusForbidden, "forbidden_error", message, err)
+}
+
+// NewNotFoundError creates a new HTTPError with a 404 status code.
+func NewNotFoundError(message string, err error) error {
+	return newHTTPError(http.StatusNotFound, "not_found_error", message, err)
+}
+
+// NewMethodNotAllowedError creates a new HTTPError with a 405 status code.
+func NewMethodNotAllowedError(message string, err error) error {
+	return newHTTPError(http.StatusMethodNotAllowed, "method_not_allowed_error", message, err)
+}
+
+// NewNotAcceptableError creates a new HTTPError with a 406 status code.
+func NewNotAcceptableError(message string, err error) error {
+	return newHTTPError(http.StatusNotAcceptable, "not_acceptable_error", message, err)
+}
+
+// NewProxyAuthRequiredError creates a new HTTPError with a 407 status code.
+func NewProxyAuthRequiredError(message string, err error) error {
+	return newHTTPError(http.StatusProxyAuthRequired, "proxy_auth_required_error", message, err)
+}
+
+// NewRequestTimeoutError creates a new HTTPError with a 408 status code.
+func NewRequestTimeoutError(message string, err error) error {
+	return newHTTPError(http.StatusRequestTimeout, "request_timeout_error", message, err)
+}
+
+// NewConflictError creates a new HTTPError with a 409 status code.
+func NewConflictError(message string, err error) error {
+	return newHTTPError(http.StatusConflict, "conflict_error", message, err)
+}
+
+// NewGoneError creates a new HTTPError with a 410 status code.
+func NewGoneError(message string, err error) error {
+	return newHTTPError(http.StatusGone, "gone_error", message, err)
+}
+
+// NewLengthRequiredError creates a new HTTPError with a 411 status code.
+func NewLengthRequiredError(message string, err error) error {
+	return newHTTPError(http.StatusLengthRequired, "length_required_error", message, err)
+}
+
+// NewPreconditionFailedError creates a new HTTPError with a 412 status code.
+func NewPreconditionFailedError(message string, err error) error {
+	return newHTTPError(http.StatusPreconditionFailed, "precondition_failed_error", message, err)
+}
+
+// NewRequestEntityTooLargeError creates a new HTTPError with a 413 status code.
+func NewRequestEntityTooLargeError(message string, err error) error {
+	return newHTTPError(http.StatusRequestEntityTooLarge, "request_entity_too_large_error", message, err)
+}
+
+// NewRequestURITooLongError creates a new HTTPError with a 414 status code.
+func NewRequestURITooLongError(message string, err error) error {
+	return newHTTPError(http.StatusRequestURITooLong, "request_uri_too_long_error", message, err)
+}
+
+// NewUnsupportedMediaTypeError creates a new HTTPError with a 415 status code.
+func NewUnsupportedMediaTypeError(message string, err error) error {
+	return newHTTPError(http.StatusUnsupportedMediaType, "unsupported_media_type_error", message, err)
+}
+
+// NewRequestedRangeNotSatisfiableError creates a new HTTPError with a 416 status code.
+func NewRequestedRangeNotSatisfiableError(message string, err error) error {
+	return newHTTPError(http.StatusRequestedRangeNotSatisfiable, "requested_range_not_satisfiable_error", message, err)
+}
+
+// NewExpectationFailedError creates a new HTTPError with a 417 status code.
+func NewExpectationFailedError(message string, err error) error {
+	return newHTTPError(http.StatusExpectationFailed, "expectation_failed_error", message, err)
+}
+
+// NewMisdirectedRequestError creates a new HTTPError with a 421 status code.
+func NewMisdirectedRequestError(message string, err error) error {
+	return newHTTPError(http.StatusMisdirectedRequest, "misdirected_request_error", message, err)
+}
+
+// NewUnprocessableEntityError creates a new HTTPError with a 422 status code.
+func NewUnprocessableEntityError(message string, err error) error {
+	return newHTTPError(http.StatusUnprocessableEntity, "unprocessable_entity_error", message, err)
+}
+
+// NewLockedError creates a new HTTPError with a 423 status code.
+func NewLockedError(message string, err error) error {
+	return newHTTPError(http.StatusLocked, "locked_error", message, err)
+}
+
+// NewFailedDependencyError creates a new HTTPError with a 424 status code.
+func NewFailedDependencyError(message string, err error) error {
+	return newHTTPError(http.StatusFailedDependency, "failed_dependency_error", message, err)
+}
+
+// NewTooEarlyError creates a new HTTPError with a 425 status code.
+func NewTooEarlyError(message string, err error) error {
+	return newHTTPError(http.StatusTooEarly, "too_early_error", message, err)
+}
+
+// NewUpgradeRequiredError creates a new HTTPError with a 426 status code.
+func NewUpgradeRequiredError(message string, err error) error {
+	return newHTTPError(http.StatusUpgradeRequired, "upgrade_required_error", message, err)
+}
+
+// NewPreconditionRequiredError creates a new HTTPError with a 428 status code.
+func NewPreconditionRequiredError(message string, err error) error {
+	return newHTTPError(http.StatusPreconditionRequired, "precondition_required_error", message, err)
+}
+
+// NewTooManyRequestsError creates a new HTTPError with a 429 status code.
+func NewTooManyRequestsError(message string, err error) error {
+	return newHTTPError(http.StatusTooManyRequests, "too_many_requests_error", message, err)
+}
+
+// NewRequestHeaderFieldsTooLargeError creates a new HTTPError with a 431 status code.
+func NewRequestHeaderFieldsTooLargeError(message string, err error) error {
+	return newHTTPError(http.StatusRequestHeaderFieldsTooLarge, "request_header_fields_too_large_error", message, err)
+}
+
+// NewUnavailableForLegalReasonsError creates a new HTTPError with a 451 status code.
+func NewUnavailableForLegalReasonsError(message string, err error) error {
+	return newHTTPError(http.StatusUnavailableForLegalReasons, "unavailable_for_legal_reasons_error", message, err)
+}
+
+// NewInternalServerError creates a new HTTPError with a 500 status code.
+func NewInternalServerError(message string, err error) error {
+	return newHTTPError(http.StatusInternalServerError, "internal_server_error", message, err)
+}
+
+// NewNotImplementedError creates a new HTTPError with a 501 status code.
+func NewNotImplementedError(message string, err error) error {
+	return newHTTPError(http.StatusNotImplemented, "not_implemented_error", message, err)
+}
+
+// NewBadGatewayError creates a new HTTPError with a 502 status code.
+func NewBadGatewayError(message string, err error) error {
+	return newHTTPError(http.StatusBadGateway, "bad_gateway_error", message, err)
+}
+
+// NewServiceUnavailableError creates a new HTTPError with a 503 status code.
+func NewServiceUnavailableError(message string, err error) error {
+	return newHTTPError(http.StatusServiceUnavailable, "service_unavailable_error", message, err)
+}
+
+// NewGatewayTimeoutError creates a new HTTPError with a 504 status code.
+func NewGatewayTimeoutError(message string, err error) error {
+	return newHTTPError(http.StatusGatewayTimeout, "gateway_timeout_error", message, err)
+}
+
+// NewHTTPVersionNotSupportedError creates a new HTTPError with a 505 status code.
+func NewHTTPVersionNotSupportedError(message string, err error) error {
+	return newHTTPError(http.StatusHTTPVersionNotSupported, "http_version_not_supported_error", message, err)
+}
+
+// NewVariantAlsoNegotiatesError creates a new HTTPError with a 506 status code.
+func NewVariantAlsoNegotiatesError(message string, err error) error {
+	return newHTTPError(http.StatusVariantAlsoNegotiates, "variant_also_negotiates_error", message, err)
+}
+
+// NewInsufficientStorageError creates a new HTTPError with a 507 status code.
+func NewInsufficientStorageError(message string, err error) error {
+	return newHTTPError(http.StatusInsufficientStorage, "insufficient_storage_error", message, err)
+}
+
+// NewLoopDetectedError creates a new HTTPError with a 508 status code.
+func NewLoopDetectedError(message string, err error) error {
+	return newHTTPError(http.StatusLoopDetected, "loop_detected_error", message, err)
+}
+
+// NewNotExtendedError creates a new HTTPError with a 510 status code.
+func NewNotExtendedError(message string, err error) error {
+	return newHTTPError(http.StatusNotExtended, "not_extended_error", message, err)
+}
+
+// NewNetworkAuthenticationRequiredError creates a new HTTPError with a 511 status code.
+func NewNetworkAuthenticationRequiredError(message string, err error) error {
+	return newHTTPError(http.StatusNetworkAuthenticationRequired, "network_authentication_required_error", message, err)
+}
@@ -0,0 +1,77 @@
+// Code generated by internal/cmd/genstatus. DO NOT EDIT.
+
+package errors
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestStatusConstructors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		fn         func(string, error) error
+		statusCode int
+		name       string
+	}{
+		{NewBadRequestError, http.StatusBadRequest, "bad_request_error"},
+		{NewUnauthorizedError, http.StatusUnauthorized, "unauthorized_error"},
+		{NewPaymentRequiredError, http.StatusPaymentRequired, "payment_required_error"},
+		{NewForbiddenError, http.StatusForbidden, "forbidden_error"},
+		{NewNotFoundError, http.StatusNotFound, "not_found_error"},
+		{NewMethodNotAllowedError, http.StatusMethodNotAllowed, "method_not_allowed_error"},
+		{NewNotAcceptableError, http.StatusNotAcceptable, "not_acceptable_error"},
+		{NewProxyAuthRequiredError, http.StatusProxyAuthRequired, "proxy_auth_required_error"},
+		{NewRequestTimeoutError, http.StatusRequestTimeout, "request_timeout_error"},
+		{NewConflictError, http.StatusConflict, "conflict_error"},
+		{NewGoneError, http.StatusGone, "gone_error"},
+		{NewLengthRequiredError, http.StatusLengthRequired, "length_required_error"},
+		{NewPreconditionFailedError, http.StatusPreconditionFailed, "precondition_failed_error"},
+		{NewRequestEntityTooLargeError, http.StatusRequestEntityTooLarge, "request_entity_too_large_error"},
+		{NewRequestURITooLongError, http.StatusRequestURITooLong, "request_uri_too_long_error"},
+		{NewUnsupportedMediaTypeError, http.StatusUnsupportedMediaType, "unsupported_media_type_error"},
+		{NewRequestedRangeNotSatisfiableError, http.StatusRequestedRangeNotSatisfiable, "requested_range_not_satisfiable_error"},
+		{NewExpectationFailedError, http.StatusExpectationFailed, "expectation_failed_error"},
+		{NewMisdirectedRequestError, http.StatusMisdirectedRequest, "misdirected_request_error"},
+		{NewUnprocessableEntityError, http.StatusUnprocessableEntity, "unprocessable_entity_error"},
+		{NewLockedError, http.StatusLocked, "locked_error"},
+		{NewFailedDependencyError, http.StatusFailedDependency, "failed_dependency_error"},
+		{NewTooEarlyError, http.StatusTooEarly, "too_early_error"},
+		{NewUpgradeRequiredError, http.StatusUpgradeRequired, "upgrade_required_error"},
+		{NewPreconditionRequiredError, http.StatusPreconditionRequired, "precondition_required_error"},
+		{NewTooManyRequestsError, http.StatusTooManyRequests, "too_many_requests_error"},
+		{NewRequestHeaderFieldsTooLargeError, http.StatusRequestHeaderFieldsTooLarge, "request_header_fields_too_large_error"},
+		{NewUnavailableForLegalReasonsError, http.StatusUnavailableForLegalReasons, "unavailable_for_legal_reasons_error"},
+		{NewInternalServerError, http.StatusInternalServerError, "internal_server_error"},
+		{NewNotImplementedError, http.StatusNotImplemented, "not_implemented_error"},
+		{NewBadGatewayError, http.StatusBadGateway, "bad_gateway_error"},
+		{NewServiceUnavailableError, http.StatusServiceUnavailable, "service_unavailable_error"},
+		{NewGatewayTimeoutError, http.StatusGatewayTimeout, "gateway_timeout_error"},
+		{NewHTTPVersionNotSupportedError, http.StatusHTTPVersionNotSupported, "http_version_not_supported_error"},
+		{NewVariantAlsoNegotiatesError, http.StatusVariantAlsoNegotiates, "variant_also_negotiates_error"},
+		{NewInsufficientStorageError, http.StatusInsufficientStorage, "insufficient_storage_error"},
+		{NewLoopDetectedError, http.StatusLoopDetected, "loop_detected_error"},
+		{NewNotExtendedError, http.StatusNotExtended, "not_extended_error"},
+		{NewNetworkAuthenticationRequiredError, http.StatusNetworkAuthenticationRequired, "network_authentication_required_error"},
+	}
+
+	if len(tests) != len(statusNames) {
+		t.Fatalf("expected %d constructors, got %d", len(statusNames), len(tests))
+	}
+
+	for _, tt := range tests {
+		got, ok := tt.fn("message", nil).(*HTTPError)
+		if !ok {
+			t.Fatalf("expected error to be of type HTTPError, got %T", got)
+		}
+
+		if got.StatusCode != tt.statusCode || got.Name != tt.name || got.Message != "message" {
+			t.Errorf("unexpected error for status %d: %s", tt.statusCode, got.JSON())
+		}
+
+		if statusNames[tt.statusCode] != tt.name {
+			t.Errorf("statusNames[%d] = %q, want %q", tt.statusCode, statusNames[tt.statusCode], tt.name)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+// Command genstatus generates the HTTPError constructors of
+// every registered 4xx and 5xx status code, along with their
+// tests, from a single table.
+//
+// It is run by go generate from the root of the module:
+//
+//	go generate ./...
+package main
+
+import (
+	"bytes"
+	"flag"
+	"go/format"
+	"log"
+	"os"
+	"text/template"
+)
+
+// status is an entry of the status table.
+type status struct {
+	// Code is the status code.
+	Code int
+
+	// Const is the name of the net/http status constant
+	// without the "Status" prefix.
+	Const string
+
+	// Func is the name of the constructor without the
+	// "New" prefix and "Error" suffix.
+	Func string
+
+	// Name is the name of the errors created by the
+	// constructor.
+	Name string
+}
+
+// statuses are the registered 4xx and 5xx status codes.
+//
+// See https://www.iana.org/assignments/http-status-codes
+var statuses = []status{
+	{400, "BadRequest", "BadRequest", "bad_request_error"},
+	{401, "Unauthorized", "Unauthorized", "unauthorized_error"},
+	{402, "PaymentRequired", "PaymentRequired", "payment_required_error"},
+	{403, "Forbidden", "Forbidden", "forbidden_error"},
+	{404, "NotFound", "NotFound", "not_found_error"},
+	{405, "MethodNotAllowed", "MethodNotAllowed", "method_not_allowed_error"},
+	{406, "NotAcceptable", "NotAcceptable", "not_acceptable_error"},
+	{407, "ProxyAuthRequired", "ProxyAuthRequired", "proxy_auth_required_error"},
+	{408, "RequestTimeout", "RequestTimeout", "request_timeout_error"},
+	{409, "Conflict", "Conflict", "conflict_error"},
+	{410, "Gone", "Gone", "gone_error"},
+	{411, "LengthRequired", "LengthRequired", "length_required_error"},
+	{412, "PreconditionFailed", "PreconditionFailed", "precondition_failed_error"},
+	{413, "RequestEntityTooLarge", "RequestEntityTooLarge", "request_entity_too_large_error"},
+	{414, "RequestURITooLong", "RequestURITooLong", "request_uri_too_long_error"},
+	{415, "UnsupportedMediaType", "UnsupportedMediaType", "unsupported_media_type_error"},
+	{416, "RequestedRangeNotSatisfiable", "RequestedRangeNotSatisfiable", "requested_range_not_satisfiable_error"},
+	{417, "ExpectationFailed", "ExpectationFailed", "expectation_failed_error"},
+	{421, "MisdirectedRequest", "MisdirectedRequest", "misdirected_request_error"},
+	{422, "UnprocessableEntity", "UnprocessableEntity", "unprocessable_entity_error"},
+	{423, "Locked", "Locked", "locked_error"},
+	{424, "FailedDependency", "FailedDependency", "failed_dependency_error"},
+	{425, "TooEarly", "TooEarly", "too_early_error"},
+	{426, "UpgradeRequired", "UpgradeRequired", "upgrade_required_error"},
+	{428, "PreconditionRequired", "PreconditionRequired", "precondition_required_error"},
+	{429, "TooManyRequests", "TooManyRequests", "too_many_requests_error"},
+	{431, "RequestHeaderFieldsTooLarge", "RequestHeaderFieldsTooLarge", "request_header_fields_too_large_error"},
+	{451, "UnavailableForLegalReasons", "UnavailableForLegalReasons", "unavailable_for_legal_reasons_error"},
+	{500, "InternalServerError", "InternalServer", "internal_server_error"},
+	{501, "NotImplemented", "NotImplemented", "not_implemented_error"},
+	{502, "BadGateway", "BadGateway", "bad_gateway_error"},
+	{503, "ServiceUnavailable", "ServiceUnavailable", "service_unavailable_error"},
+	{504, "GatewayTimeout", "GatewayTimeout", "gateway_timeout_error"},
+	{505, "HTTPVersionNotSupported", "HTTPVersionNotSupported", "http_version_not_supported_error"},
+	{506, "VariantAlsoNegotiates", "VariantAlsoNegotiates", "variant_also_negotiates_error"},
+	{507, "InsufficientStorage", "InsufficientStorage", "insufficient_storage_error"},
+	{508, "LoopDetected", "LoopDetected", "loop_detected_error"},
+	{510, "NotExtended", "NotExtended", "not_extended_error"},
+	{511, "NetworkAuthenticationRequired", "NetworkAuthenticationRequired", "network_authentication_required_error"},
+}
+
+var source = template.Must(template.New("source").Parse(`// Code generated by internal/cmd/genstatus. DO NOT EDIT.
+
+package errors
+
+import "net/http"
+
+// statusNames are the names of the errors created by the
+// constructors of every registered 4xx and 5xx status code.
+var statusNames = map[int]string{
+{{- range .}}
+	http.Status{{.Const}}: "{{.Name}}",
+{{- end}}
+}
+{{range .}}
+// New{{.Func}}Error creates a new HTTPError with a {{.Code}} status code.
+func New{{.Func}}Error(message string, err error) error {
+	return newHTTPError(http.Status{{.Const}}, "{{.Name}}", message, err)
+}
+{{end}}`))
+
+var test = template.Must(template.New("test").Parse(`// Code generated by internal/cmd/genstatus. DO NOT EDIT.
+
+package errors
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestStatusConstructors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		fn         func(string, error) error
+		statusCode int
+		name       string
+	}{
+{{- range .}}
+		{New{{.Func}}Error, http.Status{{.Const}}, "{{.Name}}"},
+{{- end}}
+	}
+
+	if len(tests) != len(statusNames) {
+		t.Fatalf("expected %d constructors, got %d", len(statusNames), len(tests))
+	}
+
+	for _, tt := range tests {
+		got, ok := tt.fn("message", nil).(*HTTPError)
+		if !ok {
+			t.Fatalf("expected error to be of type HTTPError, got %T", got)
+		}
+
+		if got.StatusCode != tt.statusCode || got.Name != tt.name || got.Message != "message" {
+			t.Errorf("unexpected error for status %d: %s", tt.statusCode, got.JSON())
+		}
+
+		if statusNames[tt.statusCode] != tt.name {
+			t.Errorf("statusNames[%d] = %q, want %q", tt.statusCode, statusNames[tt.statusCode], tt.name)
+		}
+	}
+}
+`))
+
+func main() {
+	out := flag.String("o", "http_status.go", "output file")
+	testOut := flag.String("t", "http_status_test.go", "test output file")
+	flag.Parse()
+
+	if err := generate(source, *out); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := generate(test, *testOut); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func generate(tmpl *template.Template, path string) error {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, statuses); err != nil {
+		return err
+	}
+
+	b, err := format.Source(buf.Bytes())
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, b, 0o644)
+}
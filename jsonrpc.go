@@ -0,0 +1,157 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// JSON-RPC 2.0 pre-defined error codes.
+//
+// See https://www.jsonrpc.org/specification#error_object
+const (
+	JSONRPCParseError     = -32700
+	JSONRPCInvalidRequest = -32600
+	JSONRPCMethodNotFound = -32601
+	JSONRPCInvalidParams  = -32602
+	JSONRPCInternalError  = -32603
+
+	// JSONRPCServerError is the generic implementation-defined
+	// server error code. Codes from -32099 to -32000 are reserved
+	// for implementation-defined server errors.
+	JSONRPCServerError = -32000
+)
+
+// JSONRPCError is a JSON-RPC 2.0 error object.
+type JSONRPCError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+var (
+	jsonrpcCodesMu sync.RWMutex
+	jsonrpcCodes   = map[string]int{}
+)
+
+// RegisterJSONRPCCode registers the JSON-RPC error code used
+// for errors with the given name.
+//
+// The code must be one of the pre-defined codes, an
+// implementation-defined server error code (-32099 to -32000)
+// or an application code outside of the reserved range
+// (-32768 to -32000).
+func RegisterJSONRPCCode(name string, code int) error {
+	if !validJSONRPCCode(code) {
+		return New(fmt.Sprintf("json-rpc code %d is reserved", code))
+	}
+
+	jsonrpcCodesMu.Lock()
+	defer jsonrpcCodesMu.Unlock()
+
+	jsonrpcCodes[name] = code
+	return nil
+}
+
+func validJSONRPCCode(code int) bool {
+	switch {
+	case code < -32768 || code > -32000:
+		return true
+	case code >= -32099:
+		return true
+	}
+
+	switch code {
+	case JSONRPCParseError,
+		JSONRPCInvalidRequest,
+		JSONRPCMethodNotFound,
+		JSONRPCInvalidParams,
+		JSONRPCInternalError:
+		return true
+	}
+
+	return false
+}
+
+// JSONRPCCode returns the JSON-RPC error code of the given error.
+//
+// Codes registered for the error name take precedence. Otherwise
+// HTTP errors with a 400 or 422 status code are mapped to -32602
+// (invalid params), other 4xx HTTP errors are mapped to -32000
+// (server error) and any other error is mapped to -32603
+// (internal error).
+func JSONRPCCode(err error) int {
+	httpErr := toHTTPError(err)
+
+	jsonrpcCodesMu.RLock()
+	code, ok := jsonrpcCodes[httpErr.Name]
+	jsonrpcCodesMu.RUnlock()
+	if ok {
+		return code
+	}
+
+	switch {
+	case httpErr.StatusCode == http.StatusBadRequest,
+		httpErr.StatusCode == http.StatusUnprocessableEntity:
+		return JSONRPCInvalidParams
+	case httpErr.StatusCode >= 400 && httpErr.StatusCode < 500:
+		return JSONRPCServerError
+	}
+
+	return JSONRPCInternalError
+}
+
+// NewJSONRPCError converts an error to a JSON-RPC error object.
+// The code is returned by JSONRPCCode and the data property
+// is set to the JSON representation of the error.
+func NewJSONRPCError(err error) JSONRPCError {
+	var e Error
+	switch v := wrap(err).(type) {
+	case Error:
+		e = v
+	default:
+		e = &GenericError{Name: "error"}
+	}
+
+	message := e.Error()
+	switch v := e.(type) {
+	case *HTTPError:
+		message = v.Message
+	case *GenericError:
+		message = v.Message
+	}
+
+	return JSONRPCError{
+		Code:    JSONRPCCode(e),
+		Message: message,
+		Data:    e.JSON(),
+	}
+}
+
+// ParseJSONRPCError decodes a JSON-RPC error object.
+//
+// When the data property contains the JSON representation of
+// an error it is returned as is. Otherwise a GenericError named
+// "jsonrpc_error" is returned with the error object message.
+func ParseJSONRPCError(b []byte) (Error, error) {
+	var rpcErr JSONRPCError
+	if err := json.Unmarshal(b, &rpcErr); err != nil {
+		return nil, NewWithNameAndErr("error", "invalid json-rpc error", err)
+	}
+
+	if len(rpcErr.Data) > 0 {
+		if e, err := FromJSON(rpcErr.Data); err == nil {
+			return e, nil
+		}
+	}
+
+	return &GenericError{
+		Name:    "jsonrpc_error",
+		Message: rpcErr.Message,
+		Details: Details{ErrorInfo{
+			Reason: fmt.Sprint(rpcErr.Code),
+			Domain: "jsonrpc",
+		}},
+	}, nil
+}
@@ -0,0 +1,122 @@
+package errors
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestRegisterJSONRPCCode(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should use the registered code of the error name", func(t *testing.T) {
+		if err := RegisterJSONRPCCode("jsonrpc_test_error", -32010); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if got := JSONRPCCode(NewWithName("jsonrpc_test_error", "message")); got != -32010 {
+			t.Fatalf("\n got:  %v\n want: %v", got, -32010)
+		}
+	})
+
+	t.Run("should reject reserved codes", func(t *testing.T) {
+		for _, code := range []int{-32768, -32500, -32100} {
+			if err := RegisterJSONRPCCode("reserved", code); err == nil {
+				t.Fatalf("expected an error for code %d", code)
+			}
+		}
+	})
+
+	t.Run("should accept pre-defined and application codes", func(t *testing.T) {
+		for _, code := range []int{JSONRPCInvalidParams, -32099, -32000, 1, -40000} {
+			if err := RegisterJSONRPCCode("jsonrpc_accepted_error", code); err != nil {
+				t.Fatalf("unexpected error for code %d: %v", code, err)
+			}
+		}
+	})
+}
+
+func TestJSONRPCCode(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{NewBadRequestError("message", nil), JSONRPCInvalidParams},
+		{NewHTTPError(http.StatusUnprocessableEntity, "validation_error", "message", nil), JSONRPCInvalidParams},
+		{NewNotFoundError("message", nil), JSONRPCServerError},
+		{NewInternalServerError("message", nil), JSONRPCInternalError},
+		{NewBadGatewayError("message", nil), JSONRPCInternalError},
+		{New("message"), JSONRPCInternalError},
+		{errors.New("message"), JSONRPCInternalError},
+	}
+
+	for _, tt := range tests {
+		if got := JSONRPCCode(tt.err); got != tt.want {
+			t.Errorf("JSONRPCCode(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestNewJSONRPCError(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should place the error JSON under data", func(t *testing.T) {
+		err := NewBadRequestError("invalid id", nil)
+		want := `{"code":-32602,"message":"invalid id","data":` + string(err.(Error).JSON()) + `}`
+
+		b, _ := json.Marshal(NewJSONRPCError(err))
+		if got := string(b); got != want {
+			t.Fatalf("\n got:  %v\n want: %v", got, want)
+		}
+	})
+
+	t.Run("should wrap errors that are not an Error", func(t *testing.T) {
+		got := NewJSONRPCError(errors.New("boom"))
+
+		if got.Code != JSONRPCInternalError || got.Message != "boom" {
+			t.Fatalf("unexpected json-rpc error: %#v", got)
+		}
+	})
+}
+
+func TestParseJSONRPCError(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should recover the error from data", func(t *testing.T) {
+		want := NewNotFoundError("user not found", nil).(Error)
+		b, _ := json.Marshal(NewJSONRPCError(want))
+
+		got, err := ParseJSONRPCError(b)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if string(got.JSON()) != string(want.JSON()) {
+			t.Fatalf("\n got:  %v\n want: %v", string(got.JSON()), string(want.JSON()))
+		}
+	})
+
+	t.Run("should fallback to a jsonrpc_error", func(t *testing.T) {
+		got, err := ParseJSONRPCError([]byte(`{"code":-32601,"message":"Method not found"}`))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if got.Error() != "jsonrpc_error: Method not found" {
+			t.Fatalf("\n got:  %v\n want: %v", got.Error(), "jsonrpc_error: Method not found")
+		}
+
+		if info, ok := FindDetail[ErrorInfo](got); !ok || info.Reason != "-32601" {
+			t.Fatalf("expected an ErrorInfo detail with the code, got %s", got.JSON())
+		}
+	})
+
+	t.Run("should fail on invalid JSON", func(t *testing.T) {
+		if _, err := ParseJSONRPCError([]byte(`nope`)); err == nil {
+			t.Fatalf("expected an error")
+		}
+	})
+}
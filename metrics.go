@@ -0,0 +1,112 @@
+package errors
+
+import (
+	"expvar"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// MetricsVar is the name of the expvar variable the error
+// metrics are published under.
+const MetricsVar = "errors"
+
+var (
+	// metricsEnabled reports whether the errors are counted.
+	metricsEnabled atomic.Bool
+
+	// publishMetrics publishes the metrics the first time
+	// they are enabled.
+	publishMetrics sync.Once
+
+	// metrics is the root map of the published metrics.
+	metrics = new(expvar.Map).Init()
+
+	createdMetrics = newMetricSet("created")
+	writtenMetrics = newMetricSet("written")
+)
+
+// metricSet counts errors by name, status code and status class.
+type metricSet struct {
+	byName   *expvar.Map
+	byStatus *expvar.Map
+	byClass  *expvar.Map
+}
+
+func newMetricSet(key string) metricSet {
+	s := metricSet{
+		byName:   new(expvar.Map).Init(),
+		byStatus: new(expvar.Map).Init(),
+		byClass:  new(expvar.Map).Init(),
+	}
+
+	m := new(expvar.Map).Init()
+	m.Set("by_name", s.byName)
+	m.Set("by_status", s.byStatus)
+	m.Set("by_class", s.byClass)
+	metrics.Set(key, m)
+
+	return s
+}
+
+// add counts an error, errors without a status code are
+// only counted by name.
+func (s metricSet) add(name string, statusCode int) {
+	s.byName.Add(name, 1)
+	if statusCode == 0 {
+		return
+	}
+
+	s.byStatus.Add(strconv.Itoa(statusCode), 1)
+	s.byClass.Add(strconv.Itoa(statusCode/100)+"xx", 1)
+}
+
+// EnableMetrics enables or disables the counting of the created
+// and written errors, which is disabled by default.
+//
+// The first time they are enabled, the metrics are published
+// through expvar under the MetricsVar name (unless a variable
+// already uses it) as follows:
+//
+//	{
+//	  "created": {"by_name": {...}, "by_status": {...}, "by_class": {...}},
+//	  "written": {"by_name": {...}, "by_status": {...}, "by_class": {...}}
+//	}
+//
+// Created errors are the ones returned by the constructors of
+// this package, Wrap, Op.Wrap and Recover. Written errors are
+// the ones written by WriteError, WriteTwirpError and
+// WriteConnectError. Counting is lock free once a name or
+// status code has been seen.
+func EnableMetrics(enabled bool) {
+	if enabled {
+		publishMetrics.Do(func() {
+			if expvar.Get(MetricsVar) == nil {
+				expvar.Publish(MetricsVar, metrics)
+			}
+		})
+	}
+
+	metricsEnabled.Store(enabled)
+}
+
+// Metrics returns the error metrics, they are the same as
+// the ones published through expvar.
+func Metrics() *expvar.Map {
+	return metrics
+}
+
+// countCreated counts a created GenericError or HTTPError.
+func countCreated(name string, statusCode int) {
+	if metricsEnabled.Load() {
+		createdMetrics.add(name, statusCode)
+	}
+}
+
+// countWritten counts an error written with the given
+// status code.
+func countWritten(err error, statusCode int) {
+	if metricsEnabled.Load() {
+		writtenMetrics.add(toHTTPError(err).Name, statusCode)
+	}
+}
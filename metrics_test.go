@@ -0,0 +1,117 @@
+package errors
+
+import (
+	"encoding/json"
+	"expvar"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// metricValue returns the value of a counter of the metrics.
+func metricValue(set, by, key string) int64 {
+	m := Metrics().Get(set).(*expvar.Map).Get(by).(*expvar.Map)
+	if v, ok := m.Get(key).(*expvar.Int); ok {
+		return v.Value()
+	}
+
+	return 0
+}
+
+// TestMetrics is not parallel since it toggles the metrics,
+// sequential tests run before the parallel ones.
+func TestMetrics(t *testing.T) {
+	EnableMetrics(true)
+	defer EnableMetrics(false)
+
+	t.Run("should count created errors", func(t *testing.T) {
+		name := metricValue("created", "by_name", "not_found_error")
+		status := metricValue("created", "by_status", "404")
+		class := metricValue("created", "by_class", "4xx")
+		generic := metricValue("created", "by_name", "metrics_error")
+
+		_ = NewNotFoundError("not found", nil)
+		_ = NewHTTPError(404, "not_found_error", "not found", nil)
+		_ = NewWithName("metrics_error", "boom")
+
+		for _, c := range []struct {
+			by, key string
+			want    int64
+		}{
+			{"by_name", "not_found_error", name + 2},
+			{"by_status", "404", status + 2},
+			{"by_class", "4xx", class + 2},
+			{"by_name", "metrics_error", generic + 1},
+		} {
+			if got := metricValue("created", c.by, c.key); got != c.want {
+				t.Fatalf("%s %s:\n got:  %v\n want: %v", c.by, c.key, got, c.want)
+			}
+		}
+	})
+
+	t.Run("should count written errors", func(t *testing.T) {
+		name := metricValue("written", "by_name", "metrics_error")
+		status := metricValue("written", "by_status", "500")
+		class := metricValue("written", "by_class", "5xx")
+
+		WriteError(httptest.NewRecorder(), nil, NewWithName("metrics_error", "boom"))
+
+		if got, want := metricValue("written", "by_name", "metrics_error"), name+1; got != want {
+			t.Fatalf("\n got:  %v\n want: %v", got, want)
+		}
+		if got, want := metricValue("written", "by_status", "500"), status+1; got != want {
+			t.Fatalf("\n got:  %v\n want: %v", got, want)
+		}
+		if got, want := metricValue("written", "by_class", "5xx"), class+1; got != want {
+			t.Fatalf("\n got:  %v\n want: %v", got, want)
+		}
+	})
+
+	t.Run("should count concurrently", func(t *testing.T) {
+		before := metricValue("created", "by_name", "concurrent_error")
+
+		var wg sync.WaitGroup
+		for range 50 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for range 100 {
+					_ = NewWithName("concurrent_error", "boom")
+				}
+			}()
+		}
+		wg.Wait()
+
+		if got, want := metricValue("created", "by_name", "concurrent_error"), before+5000; got != want {
+			t.Fatalf("\n got:  %v\n want: %v", got, want)
+		}
+	})
+
+	t.Run("should publish the metrics through expvar", func(t *testing.T) {
+		v := expvar.Get(MetricsVar)
+		if v == nil {
+			t.Fatalf("expected the metrics to be published")
+		}
+
+		var got map[string]map[string]map[string]int64
+		if err := json.Unmarshal([]byte(v.String()), &got); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if got["created"]["by_name"]["concurrent_error"] == 0 {
+			t.Fatalf("expected the published metrics to be counted, got %s", v)
+		}
+	})
+
+	t.Run("should not count when disabled", func(t *testing.T) {
+		EnableMetrics(false)
+		defer EnableMetrics(true)
+
+		before := metricValue("created", "by_name", "disabled_error")
+		_ = NewWithName("disabled_error", "boom")
+
+		if got := metricValue("created", "by_name", "disabled_error"); got != before {
+			t.Fatalf("\n got:  %v\n want: %v", got, before)
+		}
+	})
+}
@@ -0,0 +1,103 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+var _ Error = &MultiError{}
+
+// MultiError is an error made of multiple labelled causes,
+// like the failed attempts of Retry or the failed goroutines
+// of a Group.
+type MultiError struct {
+	// Name is the name of the error
+	Name string `json:"name"`
+
+	// Message is the message of the error
+	Message string `json:"message"`
+
+	// Causes are the labelled errors
+	Causes []Cause `json:"errors"`
+}
+
+// Cause is a labelled cause of a MultiError.
+type Cause struct {
+	// Label identifies the cause (e.g. "attempt 1")
+	Label string `json:"label,omitempty"`
+
+	// Err is the error of the cause
+	Err error `json:"error"`
+}
+
+// Error returns a string concatenation of the name, message
+// and every labelled cause.
+func (e *MultiError) Error() string {
+	if len(e.Causes) == 0 {
+		return fmt.Sprintf("%s: %s", e.Name, e.Message)
+	}
+
+	causes := make([]string, 0, len(e.Causes))
+	for _, c := range e.Causes {
+		msg := "<nil>"
+		if c.Err != nil {
+			msg = c.Err.Error()
+		}
+
+		if c.Label == "" {
+			causes = append(causes, msg)
+			continue
+		}
+		causes = append(causes, c.Label+": "+msg)
+	}
+
+	return fmt.Sprintf("%s: %s (%s)", e.Name, e.Message, strings.Join(causes, "; "))
+}
+
+// Unwrap returns the errors of every cause so the standard
+// errors.Is and errors.As functions inspect all of them.
+func (e *MultiError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Causes))
+	for _, c := range e.Causes {
+		if c.Err != nil {
+			errs = append(errs, c.Err)
+		}
+	}
+
+	return errs
+}
+
+// JSON returns the JSON representation of the error.
+//
+// Every cause error is rendered with it's own JSON() method,
+// errors that are not an Error are wrapped first.
+func (e MultiError) JSON() []byte {
+	type cause struct {
+		Label string          `json:"label,omitempty"`
+		Err   json.RawMessage `json:"error"`
+	}
+
+	causes := make([]cause, 0, len(e.Causes))
+	for _, c := range e.Causes {
+		raw := json.RawMessage("null")
+		if c.Err != nil {
+			raw = wrap(c.Err).(Error).JSON()
+		}
+		causes = append(causes, cause{Label: c.Label, Err: raw})
+	}
+
+	b, _ := json.Marshal(struct {
+		Name        string  `json:"name"`
+		Message     string  `json:"message"`
+		Causes      []cause `json:"errors"`
+		Fingerprint string  `json:"fingerprint,omitempty"`
+	}{
+		Name:        e.Name,
+		Message:     e.Message,
+		Causes:      causes,
+		Fingerprint: jsonFingerprint(&e),
+	})
+
+	return b
+}
@@ -0,0 +1,70 @@
+package errors
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestMultiError_Error(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should list every labelled cause", func(t *testing.T) {
+		err := &MultiError{
+			Name:    "multi_error",
+			Message: "2 failures",
+			Causes: []Cause{
+				{Label: "a", Err: New("first")},
+				{Err: errors.New("second")},
+			},
+		}
+
+		want := "multi_error: 2 failures (a: error: first; second)"
+		if got := err.Error(); got != want {
+			t.Fatalf("\n got:  %v\n want: %v", got, want)
+		}
+	})
+
+	t.Run("should return the name and message without causes", func(t *testing.T) {
+		err := &MultiError{Name: "multi_error", Message: "no failures"}
+
+		if got := err.Error(); got != "multi_error: no failures" {
+			t.Fatalf("\n got:  %v\n want: %v", got, "multi_error: no failures")
+		}
+	})
+}
+
+func TestMultiError_JSON(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should render every cause with its JSON representation", func(t *testing.T) {
+		notFound := NewNotFoundError("not found", nil)
+		plain := errors.New("plain")
+		err := &MultiError{
+			Name:    "multi_error",
+			Message: "2 failures",
+			Causes:  []Cause{{Label: "a", Err: notFound}, {Label: "b", Err: plain}},
+		}
+
+		want := `{"name":"multi_error","message":"2 failures","errors":[` +
+			`{"label":"a","error":` + string(notFound.(Error).JSON()) + `},` +
+			`{"label":"b","error":` + string(Wrap(plain).(Error).JSON()) + `}]}`
+
+		if got := string(err.JSON()); got != want {
+			t.Fatalf("\n got:  %v\n want: %v", got, want)
+		}
+	})
+}
+
+func TestMultiError_Unwrap(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should expose every cause to errors.As", func(t *testing.T) {
+		err := &MultiError{Causes: []Cause{{Err: New("first")}, {Err: NewConflictError("conflict", nil)}}}
+
+		var httpErr *HTTPError
+		if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusConflict {
+			t.Fatalf("expected to find the HTTPError cause")
+		}
+	})
+}
@@ -0,0 +1,67 @@
+package errors
+
+import "strings"
+
+// Op is the name of a logical operation (e.g. "users.Get")
+// recorded on errors as they are returned up the call stack.
+//
+//	const op errors.Op = "users.Get"
+//
+//	user, err := repo.Find(ctx, id)
+//	if err != nil {
+//		return nil, op.Wrap(err)
+//	}
+type Op string
+
+// Wrap returns a copy of the given error with the operation
+// prepended to it's operation trail. Errors that are not a
+// GenericError or an HTTPError are wrapped in a GenericError
+// first. A nil error returns nil.
+func (op Op) Wrap(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	pc := returnPC()
+	if annotated, ok := annotate(err, op, pc); ok {
+		return annotated
+	}
+
+	countCreated("error", 0)
+	return &GenericError{
+		Name:     "error",
+		Message:  err.Error(),
+		Original: err,
+		Ops:      []string{string(op)},
+		returns:  appendPC(nil, pc),
+	}
+}
+
+// Ops returns the operation trail of the outermost
+// GenericError or HTTPError of the error chain.
+func Ops(err error) []string {
+	for depth := 0; err != nil && depth < maxErrorDepth; depth++ {
+		switch e := err.(type) {
+		case *GenericError:
+			return e.Ops
+		case GenericError:
+			return e.Ops
+		case *HTTPError:
+			return e.Ops
+		}
+
+		err = unwrapOnce(err)
+	}
+
+	return nil
+}
+
+// opsPrefix returns the operation trail followed by a
+// separator, or an empty string without operations.
+func opsPrefix(ops []string) string {
+	if len(ops) == 0 {
+		return ""
+	}
+
+	return strings.Join(ops, ": ") + ": "
+}
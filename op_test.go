@@ -0,0 +1,125 @@
+package errors
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestOpWrap(t *testing.T) {
+	t.Parallel()
+
+	const (
+		get  Op = "users.Get"
+		find Op = "repo.Find"
+	)
+
+	t.Run("should render the trail in Error", func(t *testing.T) {
+		err := get.Wrap(find.Wrap(NewNotFoundError("user not found", nil)))
+
+		got := err.Error()
+		want := "users.Get: repo.Find: not_found_error: user not found"
+		if got != want {
+			t.Fatalf("\n got:  %v\n want: %v", got, want)
+		}
+	})
+
+	t.Run("should render the trail in JSON", func(t *testing.T) {
+		err := get.Wrap(find.Wrap(New("boom"))).(Error)
+
+		got := string(err.JSON())
+		want := `{"name":"error","message":"boom","ops":["users.Get","repo.Find"]}`
+		if got != want {
+			t.Fatalf("\n got:  %v\n want: %v", got, want)
+		}
+	})
+
+	t.Run("should decode the trail from JSON", func(t *testing.T) {
+		err := get.Wrap(NewBadRequestError("invalid id", nil)).(Error)
+
+		decoded, decodeErr := FromJSON(err.JSON())
+		if decodeErr != nil {
+			t.Fatalf("unexpected error: %v", decodeErr)
+		}
+
+		got := Ops(decoded)
+		want := []string{"users.Get"}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("\n got:  %v\n want: %v", got, want)
+		}
+	})
+
+	t.Run("should not modify the wrapped error", func(t *testing.T) {
+		inner := New("boom").(*GenericError)
+		_ = get.Wrap(find.Wrap(inner))
+
+		if len(inner.Ops) != 0 {
+			t.Fatalf("expected the wrapped error to be unchanged, got %v", inner.Ops)
+		}
+	})
+
+	t.Run("should not share the header and details of the wrapped error", func(t *testing.T) {
+		sentinel := NewServiceUnavailableError("unavailable", nil).(*HTTPError).
+			WithHeader("Retry-After", "1").
+			WithDetails(ErrorInfo{Reason: "A"})
+		sentinel.Details = append(make(Details, 0, 4), sentinel.Details...)
+
+		wrapped := get.Wrap(sentinel).(*HTTPError).
+			WithHeader("Retry-After", "60").
+			WithDetails(ErrorInfo{Reason: "B"})
+		_ = find.Wrap(sentinel).(*HTTPError).WithDetails(ErrorInfo{Reason: "C"})
+
+		if got, want := sentinel.Header.Get("Retry-After"), "1"; got != want {
+			t.Fatalf("\n got:  %v\n want: %v", got, want)
+		}
+		if got, want := sentinel.Details, (Details{ErrorInfo{Reason: "A"}}); !reflect.DeepEqual(got, want) {
+			t.Fatalf("\n got:  %v\n want: %v", got, want)
+		}
+		if got, want := wrapped.Details, (Details{ErrorInfo{Reason: "A"}, ErrorInfo{Reason: "B"}}); !reflect.DeepEqual(got, want) {
+			t.Fatalf("\n got:  %v\n want: %v", got, want)
+		}
+	})
+
+	t.Run("should wrap errors that are not from this package", func(t *testing.T) {
+		cause := errors.New("connection refused")
+		err := find.Wrap(cause)
+
+		got := err.Error()
+		want := "repo.Find: error: connection refused (connection refused)"
+		if got != want {
+			t.Fatalf("\n got:  %v\n want: %v", got, want)
+		}
+
+		if unwrapOnce(err) != cause {
+			t.Fatalf("expected the cause to be kept in the chain")
+		}
+	})
+
+	t.Run("should return nil for a nil error", func(t *testing.T) {
+		if err := get.Wrap(nil); err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+	})
+
+	t.Run("should keep the HTTP status and name", func(t *testing.T) {
+		err, ok := get.Wrap(NewForbiddenError("denied", nil)).(*HTTPError)
+		if !ok {
+			t.Fatalf("expected error to be of type HTTPError, got %T", err)
+		}
+
+		if err.StatusCode != 403 || !strings.HasPrefix(err.Error(), "users.Get: forbidden_error") {
+			t.Fatalf("unexpected error: %s", err.JSON())
+		}
+	})
+}
+
+func TestOps(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should return nil without a trail", func(t *testing.T) {
+		if got := Ops(errors.New("boom")); got != nil {
+			t.Fatalf("\n got:  %v\n want: %v", got, nil)
+		}
+	})
+}
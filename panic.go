@@ -0,0 +1,148 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+var _ Error = PanicValue{}
+
+// PanicValue holds a recovered panic value that is not an
+// error, so it can be kept as the Original error of a panic
+// GenericError.
+type PanicValue struct {
+	Value any `json:"value"`
+}
+
+// Error returns the panic value formatted with fmt.Sprint.
+func (v PanicValue) Error() string {
+	return fmt.Sprint(v.Value)
+}
+
+// MarshalJSON marshals the panic value. If the value is not
+// marshallable, it is replaced by it's fmt.Sprint string.
+func (v PanicValue) MarshalJSON() ([]byte, error) {
+	type value PanicValue
+	if b, err := json.Marshal(value(v)); err == nil {
+		return b, nil
+	}
+
+	return json.Marshal(value{Value: v.Error()})
+}
+
+// JSON returns the JSON representation of the panic value.
+func (v PanicValue) JSON() []byte {
+	b, _ := v.MarshalJSON()
+	return b
+}
+
+// newPanicError converts a recovered panic value into a
+// GenericError named "panic" with the stack trace at the
+// panic site. It must be called from the deferred function
+// that recovered the panic.
+//
+// Panic values that are errors are wrapped as the Original
+// error, any other value is kept in a PanicValue.
+func newPanicError(v any) *GenericError {
+	var orig error = PanicValue{Value: v}
+	if err, ok := v.(error); ok {
+		orig = wrap(err)
+	}
+
+	countCreated("panic", 0)
+	return &GenericError{
+		Name:     "panic",
+		Message:  fmt.Sprint(v),
+		Original: orig,
+		Stack:    panicStack(),
+	}
+}
+
+// Recover converts a panic into a GenericError named "panic"
+// and stores it in err. It must be deferred directly:
+//
+//	func job() (err error) {
+//		defer errors.Recover(&err)
+//		// ...
+//	}
+//
+// The error keeps the panic value as it's Original error
+// and the stack trace at the panic site. The panic is not
+// recovered when err is nil.
+func Recover(err *error) {
+	v := recover()
+	if v == nil {
+		return
+	}
+	if err == nil {
+		panic(v)
+	}
+
+	*err = newPanicError(v)
+}
+
+// Safe calls fn and returns it's error. If fn panics, the
+// panic is returned as a GenericError named "panic".
+func Safe(fn func() error) (err error) {
+	defer Recover(&err)
+
+	return fn()
+}
+
+// Middleware returns an http.Handler that recovers the panics
+// of next and writes them with WriteError as a 500 HTTPError
+// wrapping the panic error.
+//
+// The http.ErrAbortHandler panic is not recovered and nothing
+// is written if the response was already started, the error
+// is then only reported to the Reporter set with SetReporter.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rw := &responseWriter{ResponseWriter: w}
+		defer func() {
+			v := recover()
+			if v == nil {
+				return
+			}
+
+			if v == http.ErrAbortHandler {
+				panic(v)
+			}
+
+			err := NewInternalServerError("internal server error", newPanicError(v))
+			if rw.wroteHeader {
+				Report(r.Context(), err)
+				return
+			}
+			WriteError(rw, r, err)
+		}()
+
+		next.ServeHTTP(rw, r)
+	})
+}
+
+// responseWriter records whether the response was started.
+type responseWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+func (w *responseWriter) WriteHeader(statusCode int) {
+	w.wroteHeader = true
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap returns the wrapped http.ResponseWriter, it is
+// used by http.ResponseController.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
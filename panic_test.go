@@ -0,0 +1,153 @@
+package errors
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRecover(t *testing.T) {
+	t.Parallel()
+
+	job := func(v any) (err error) {
+		defer Recover(&err)
+		panic(v)
+	}
+
+	t.Run("should convert a string panic", func(t *testing.T) {
+		err, ok := job("boom").(*GenericError)
+		if !ok {
+			t.Fatalf("expected error to be of type GenericError, got %T", err)
+		}
+
+		if err.Name != "panic" || err.Message != "boom" {
+			t.Fatalf("unexpected panic error: %s", err.JSON())
+		}
+
+		if !strings.Contains(string(err.JSON()), `"original":{"value":"boom"}`) {
+			t.Fatalf("expected the panic value in original, got %s", err.JSON())
+		}
+
+		if len(err.Stack) == 0 || !strings.Contains(err.Stack[0].Function, "TestRecover") {
+			t.Fatalf("expected the stack to start at the panic site, got:\n%s", err.Stack)
+		}
+	})
+
+	t.Run("should not recover the panic without an error", func(t *testing.T) {
+		defer func() {
+			if v := recover(); v != "boom" {
+				t.Fatalf("\n got:  %v\n want: %v", v, "boom")
+			}
+		}()
+
+		func() {
+			defer Recover(nil)
+			panic("boom")
+		}()
+
+		t.Fatalf("expected the panic to propagate")
+	})
+
+	t.Run("should wrap an error panic", func(t *testing.T) {
+		cause := errors.New("cause")
+		err := job(cause).(*GenericError)
+
+		if err.Message != "cause" || !errors.Is(unwrapOnce(err).(*GenericError).Original, cause) {
+			t.Fatalf("expected the panic error in original, got %s", err.JSON())
+		}
+	})
+
+	t.Run("should make arbitrary values marshallable", func(t *testing.T) {
+		err := job(struct{ Fn func() }{Fn: func() {}}).(*GenericError)
+
+		if !strings.Contains(string(err.JSON()), `"original":{"value":"{`) {
+			t.Fatalf("expected the formatted panic value in original, got %s", err.JSON())
+		}
+	})
+
+	t.Run("should keep the error when there is no panic", func(t *testing.T) {
+		want := New("error")
+		got := func() (err error) {
+			defer Recover(&err)
+			return want
+		}()
+
+		if got != want {
+			t.Fatalf("\n got:  %v\n want: %v", got, want)
+		}
+	})
+}
+
+func TestSafe(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should return the panic as an error", func(t *testing.T) {
+		err := Safe(func() error {
+			var m map[string]int
+			m["key"] = 1
+			return nil
+		})
+
+		if err == nil || err.(*GenericError).Name != "panic" {
+			t.Fatalf("expected a panic error, got %v", err)
+		}
+	})
+
+	t.Run("should return the error of fn", func(t *testing.T) {
+		want := New("error")
+		if got := Safe(func() error { return want }); got != want {
+			t.Fatalf("\n got:  %v\n want: %v", got, want)
+		}
+	})
+}
+
+func TestMiddleware(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should write panics as internal server errors", func(t *testing.T) {
+		h := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic("boom")
+		}))
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		if rec.Code != http.StatusInternalServerError {
+			t.Fatalf("\n got:  %v\n want: %v", rec.Code, http.StatusInternalServerError)
+		}
+
+		if !strings.Contains(rec.Body.String(), `"name":"panic"`) {
+			t.Fatalf("expected the panic error in the body, got %s", rec.Body.String())
+		}
+	})
+
+	t.Run("should not write when the response was started", func(t *testing.T) {
+		h := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("partial"))
+			panic("boom")
+		}))
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		if rec.Code != http.StatusOK || rec.Body.String() != "partial" {
+			t.Fatalf("unexpected response: %d %s", rec.Code, rec.Body.String())
+		}
+	})
+
+	t.Run("should propagate http.ErrAbortHandler", func(t *testing.T) {
+		h := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic(http.ErrAbortHandler)
+		}))
+
+		defer func() {
+			if v := recover(); v != http.ErrAbortHandler {
+				t.Fatalf("expected http.ErrAbortHandler, got %v", v)
+			}
+		}()
+
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+}
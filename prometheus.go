@@ -0,0 +1,280 @@
+package errors
+
+import (
+	"bufio"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultPrometheusBuckets are the default histogram buckets
+// of PrometheusMetrics, in seconds.
+var DefaultPrometheusBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// PrometheusConfig configures PrometheusMetrics. Zero values
+// use the documented defaults.
+type PrometheusConfig struct {
+	// Namespace is the optional prefix of the metric names,
+	// joined to them with an underscore.
+	Namespace string
+
+	// Buckets are the upper bounds of the duration histogram
+	// buckets in seconds. Defaults to DefaultPrometheusBuckets.
+	Buckets []float64
+}
+
+// PrometheusMetrics counts the error responses of an HTTP
+// server and exposes them in the Prometheus text exposition
+// format, without depending on the Prometheus client library.
+//
+// Errors are recorded by it's Middleware and exposed by it's
+// Handler as the following metrics, labelled with the error
+// name, the route pattern of the request and the status code:
+//
+//   - http_errors_total, a counter of the error responses,
+//   - http_error_duration_seconds, a histogram of the duration
+//     of the requests answered with an error.
+//
+// It is safe for concurrent use.
+type PrometheusMetrics struct {
+	cfg PrometheusConfig
+	now func() time.Time
+
+	series sync.Map
+}
+
+// promSeries is the data of a single label set.
+type promSeries struct {
+	name, route, status string
+
+	buckets []atomic.Uint64
+	count   atomic.Uint64
+	sum     atomic.Uint64
+}
+
+// NewPrometheusMetrics creates a new PrometheusMetrics. The
+// buckets are sorted and deduplicated, an error is returned
+// when one of them is NaN or infinite.
+func NewPrometheusMetrics(cfg PrometheusConfig) (*PrometheusMetrics, error) {
+	if len(cfg.Buckets) == 0 {
+		cfg.Buckets = DefaultPrometheusBuckets
+	}
+
+	for _, b := range cfg.Buckets {
+		if math.IsNaN(b) || math.IsInf(b, 0) {
+			return nil, New("prometheus metrics: invalid bucket " + strconv.FormatFloat(b, 'g', -1, 64))
+		}
+	}
+
+	cfg.Buckets = slices.Clone(cfg.Buckets)
+	slices.Sort(cfg.Buckets)
+	cfg.Buckets = slices.Compact(cfg.Buckets)
+
+	return &PrometheusMetrics{cfg: cfg, now: time.Now}, nil
+}
+
+// Middleware returns an http.Handler recording the responses
+// of next with a 4xx or 5xx status code.
+//
+// The error name is the one of the error written by WriteError,
+// WriteTwirpError or WriteConnectError, or the name derived
+// from the status code when the response was written otherwise.
+// The route is the pattern matched by the http.ServeMux
+// (e.g. "GET /users/{id}"), it must therefore wrap the mux.
+// To record recovered panics, it must also wrap Middleware:
+//
+//	metrics.Middleware(errors.Middleware(mux))
+func (m *PrometheusMetrics) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := m.now()
+		rw := &statusWriter{ResponseWriter: w}
+
+		next.ServeHTTP(rw, r)
+
+		if rw.statusCode < 400 {
+			return
+		}
+
+		name := rw.errName
+		if name == "" {
+			name = statusName(rw.statusCode)
+		}
+
+		m.observe(name, r.Pattern, rw.statusCode, m.now().Sub(start))
+	})
+}
+
+func (m *PrometheusMetrics) observe(name, route string, statusCode int, d time.Duration) {
+	status := strconv.Itoa(statusCode)
+	key := name + "\xff" + route + "\xff" + status
+
+	v, ok := m.series.Load(key)
+	if !ok {
+		v, _ = m.series.LoadOrStore(key, &promSeries{
+			name:    name,
+			route:   route,
+			status:  status,
+			buckets: make([]atomic.Uint64, len(m.cfg.Buckets)),
+		})
+	}
+	s := v.(*promSeries)
+
+	seconds := d.Seconds()
+	if i, _ := slices.BinarySearch(m.cfg.Buckets, seconds); i < len(s.buckets) {
+		s.buckets[i].Add(1)
+	}
+	s.count.Add(1)
+
+	for {
+		old := s.sum.Load()
+		sum := math.Float64bits(math.Float64frombits(old) + seconds)
+		if s.sum.CompareAndSwap(old, sum) {
+			break
+		}
+	}
+}
+
+// Handler returns an http.Handler writing the metrics in the
+// Prometheus text exposition format.
+func (m *PrometheusMetrics) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+		bw := bufio.NewWriter(w)
+		m.write(bw)
+		_ = bw.Flush()
+	})
+}
+
+// write writes the metrics with the series sorted by labels.
+func (m *PrometheusMetrics) write(w *bufio.Writer) {
+	var series []*promSeries
+	m.series.Range(func(_, v any) bool {
+		series = append(series, v.(*promSeries))
+		return true
+	})
+	slices.SortFunc(series, func(a, b *promSeries) int {
+		if c := strings.Compare(a.name, b.name); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a.route, b.route); c != 0 {
+			return c
+		}
+		return strings.Compare(a.status, b.status)
+	})
+
+	total := m.metricName("http_errors_total")
+	w.WriteString("# HELP " + total + " Error responses by error name, route and status code.\n")
+	w.WriteString("# TYPE " + total + " counter\n")
+	for _, s := range series {
+		w.WriteString(total + s.labels("") + " " + strconv.FormatUint(s.count.Load(), 10) + "\n")
+	}
+
+	duration := m.metricName("http_error_duration_seconds")
+	w.WriteString("# HELP " + duration + " Duration of the requests answered with an error by error name, route and status code.\n")
+	w.WriteString("# TYPE " + duration + " histogram\n")
+	for _, s := range series {
+		var cumulative uint64
+		for i, upper := range m.cfg.Buckets {
+			cumulative += s.buckets[i].Load()
+			w.WriteString(duration + "_bucket" + s.labels(formatFloat(upper)) + " " + strconv.FormatUint(cumulative, 10) + "\n")
+		}
+
+		count := strconv.FormatUint(s.count.Load(), 10)
+		w.WriteString(duration + "_bucket" + s.labels("+Inf") + " " + count + "\n")
+		w.WriteString(duration + "_sum" + s.labels("") + " " + formatFloat(math.Float64frombits(s.sum.Load())) + "\n")
+		w.WriteString(duration + "_count" + s.labels("") + " " + count + "\n")
+	}
+}
+
+func (m *PrometheusMetrics) metricName(name string) string {
+	if m.cfg.Namespace == "" {
+		return name
+	}
+
+	return m.cfg.Namespace + "_" + name
+}
+
+// labels returns the label set of the series, with the
+// le label when it is not empty.
+func (s *promSeries) labels(le string) string {
+	labels := `{name="` + escapeLabelValue(s.name) +
+		`",route="` + escapeLabelValue(s.route) +
+		`",status="` + s.status + `"`
+	if le != "" {
+		labels += `,le="` + le + `"`
+	}
+
+	return labels + "}"
+}
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabelValue(v string) string {
+	return labelValueReplacer.Replace(v)
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// errorRecorder is implemented by the response writers
+// recording the errors written to them.
+type errorRecorder interface {
+	recordError(err error)
+}
+
+// recordError records the given error on the first
+// errorRecorder of the response writer chain of w.
+func recordError(w http.ResponseWriter, err error) {
+	for depth := 0; w != nil && depth < maxErrorDepth; depth++ {
+		if r, ok := w.(errorRecorder); ok {
+			r.recordError(err)
+			return
+		}
+
+		u, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			return
+		}
+		w = u.Unwrap()
+	}
+}
+
+// statusWriter records the status code and the error
+// written to the response.
+type statusWriter struct {
+	http.ResponseWriter
+	statusCode int
+	errName    string
+}
+
+func (w *statusWriter) WriteHeader(statusCode int) {
+	if w.statusCode == 0 && statusCode >= 200 {
+		w.statusCode = statusCode
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.statusCode == 0 {
+		w.statusCode = http.StatusOK
+	}
+
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *statusWriter) recordError(err error) {
+	w.errName = toHTTPError(err).Name
+}
+
+// Unwrap returns the wrapped http.ResponseWriter, it is
+// used by http.ResponseController.
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
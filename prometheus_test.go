@@ -0,0 +1,147 @@
+package errors
+
+import (
+	"flag"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+// testGolden compares got with the given golden file of the
+// testdata directory, updating it with the -update flag.
+func testGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if string(got) != string(want) {
+		t.Fatalf("\n got:\n%s\n want:\n%s", got, want)
+	}
+}
+
+// newTestPrometheusMetrics returns metrics measuring every
+// request as taking the given duration.
+func newTestPrometheusMetrics(t *testing.T, cfg PrometheusConfig, d time.Duration) *PrometheusMetrics {
+	t.Helper()
+
+	m, err := NewPrometheusMetrics(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	now := time.Unix(0, 0)
+	m.now = func() time.Time {
+		now = now.Add(d)
+		return now
+	}
+
+	return m
+}
+
+func scrape(t *testing.T, m *PrometheusMetrics) []byte {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if got, want := rec.Header().Get("Content-Type"), "text/plain; version=0.0.4; charset=utf-8"; got != want {
+		t.Fatalf("\n got:  %v\n want: %v", got, want)
+	}
+
+	return rec.Body.Bytes()
+}
+
+func TestPrometheusMetrics(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should expose the recorded errors", func(t *testing.T) {
+		m := newTestPrometheusMetrics(t, PrometheusConfig{Buckets: []float64{0.1, 0.5, 1}}, 250*time.Millisecond)
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("GET /users/{id}", func(w http.ResponseWriter, r *http.Request) {
+			WriteError(w, r, NewNotFoundError("user not found", nil))
+		})
+		mux.HandleFunc("GET /panic", func(w http.ResponseWriter, r *http.Request) {
+			panic("boom")
+		})
+		mux.HandleFunc("GET /plain", func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "bad request", http.StatusBadRequest)
+		})
+		mux.HandleFunc("GET /ok", func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("ok"))
+		})
+		handler := m.Middleware(Middleware(mux))
+
+		for _, path := range []string{"/users/1", "/users/2", "/panic", "/plain", "/ok", "/missing"} {
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+		}
+
+		testGolden(t, "prometheus.golden", scrape(t, m))
+	})
+
+	t.Run("should sort and deduplicate the buckets", func(t *testing.T) {
+		m := newTestPrometheusMetrics(t, PrometheusConfig{Buckets: []float64{1, 0.5, 1, 0.5}}, 0)
+
+		if got, want := m.cfg.Buckets, []float64{0.5, 1}; !slices.Equal(got, want) {
+			t.Fatalf("\n got:  %v\n want: %v", got, want)
+		}
+	})
+
+	t.Run("should reject the invalid buckets", func(t *testing.T) {
+		for _, b := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+			if _, err := NewPrometheusMetrics(PrometheusConfig{Buckets: []float64{1, b}}); err == nil {
+				t.Fatalf("expected an error for the %v bucket", b)
+			}
+		}
+	})
+
+	t.Run("should prefix the metrics with the namespace", func(t *testing.T) {
+		m := newTestPrometheusMetrics(t, PrometheusConfig{Namespace: "api", Buckets: []float64{1}}, time.Second)
+
+		handler := m.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			WriteError(w, r, NewWithName("custom_error", "boom"))
+		}))
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+		testGolden(t, "prometheus_namespace.golden", scrape(t, m))
+	})
+
+	t.Run("should escape the label values", func(t *testing.T) {
+		m := newTestPrometheusMetrics(t, PrometheusConfig{}, 0)
+		m.observe("a\"b\\c\nd", "", 500, time.Millisecond)
+
+		want := `http_errors_total{name="a\"b\\c\nd",route="",status="500"} 1`
+		if got := string(scrape(t, m)); !strings.Contains(got, want) {
+			t.Fatalf("expected %s in:\n%s", want, got)
+		}
+	})
+
+	t.Run("should expose only the headers without errors", func(t *testing.T) {
+		got := string(scrape(t, newTestPrometheusMetrics(t, PrometheusConfig{}, 0)))
+		want := "# HELP http_errors_total Error responses by error name, route and status code.\n" +
+			"# TYPE http_errors_total counter\n" +
+			"# HELP http_error_duration_seconds Duration of the requests answered with an error by error name, route and status code.\n" +
+			"# TYPE http_error_duration_seconds histogram\n"
+		if got != want {
+			t.Fatalf("\n got:  %v\n want: %v", got, want)
+		}
+	})
+}
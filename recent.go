@@ -0,0 +1,248 @@
+package errors
+
+import (
+	"bytes"
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// RecentError is an error recorded by the recent errors
+// buffer when it was written to an HTTP response.
+type RecentError struct {
+	// Seq is the sequence number of the error, it increases
+	// with every recorded error.
+	Seq uint64 `json:"seq"`
+
+	// Time is the time the error was written.
+	Time time.Time `json:"time"`
+
+	// Method, Path and Route are the method, the URL path and
+	// the route pattern of the request, when they are known.
+	Method string `json:"method,omitempty"`
+	Path   string `json:"path,omitempty"`
+	Route  string `json:"route,omitempty"`
+
+	// StatusCode is the status code of the response.
+	StatusCode int `json:"statusCode"`
+
+	// Name is the name of the error.
+	Name string `json:"name"`
+
+	// Error is the JSON representation of the error chain.
+	Error json.RawMessage `json:"error"`
+
+	// Stack is the stack trace of the error, or the one of
+	// the call writing it when the error has none.
+	Stack Stack `json:"stack,omitempty"`
+}
+
+// recentBuffer is a fixed size ring buffer. Writers reserve
+// a slot with an atomic counter and replace it's content, no
+// lock is taken.
+type recentBuffer struct {
+	next  atomic.Uint64
+	slots []atomic.Pointer[RecentError]
+}
+
+// recent is the buffer of the recent errors, nil when the
+// recording is disabled.
+var recent atomic.Pointer[recentBuffer]
+
+// EnableRecentErrors enables the recording of the last size
+// errors written by WriteError, WriteTwirpError and
+// WriteConnectError, which is disabled by default. A zero
+// or negative size disables it. The previously recorded
+// errors are discarded.
+func EnableRecentErrors(size int) {
+	if size <= 0 {
+		recent.Store(nil)
+		return
+	}
+
+	recent.Store(&recentBuffer{slots: make([]atomic.Pointer[RecentError], size)})
+}
+
+// recordRecent records a written error when the recording is
+// enabled. It must be called by onWritten.
+func recordRecent(r *http.Request, err error, statusCode int) {
+	buf := recent.Load()
+	if buf == nil {
+		return
+	}
+
+	e := &RecentError{
+		Time:       time.Now(),
+		StatusCode: statusCode,
+		Name:       toHTTPError(err).Name,
+		Error:      wrap(err).(Error).JSON(),
+		Stack:      stackOf(err),
+	}
+	if len(e.Stack) == 0 {
+		e.Stack = callers(3)
+	}
+	if r != nil {
+		e.Method = r.Method
+		e.Path = r.URL.Path
+		e.Route = r.Pattern
+	}
+
+	e.Seq = buf.next.Add(1)
+	buf.slots[(e.Seq-1)%uint64(len(buf.slots))].Store(e)
+}
+
+// stackOf returns the first stack trace of the error chain.
+func stackOf(err error) Stack {
+	for depth := 0; err != nil && depth < maxErrorDepth; depth++ {
+		switch e := err.(type) {
+		case *GenericError:
+			if len(e.Stack) > 0 {
+				return e.Stack
+			}
+		case GenericError:
+			if len(e.Stack) > 0 {
+				return e.Stack
+			}
+		}
+
+		err = unwrapOnce(err)
+	}
+
+	return nil
+}
+
+// RecentErrors returns the recorded errors, the most recent
+// first. It returns nil when the recording is disabled.
+func RecentErrors() []RecentError {
+	buf := recent.Load()
+	if buf == nil {
+		return nil
+	}
+
+	errs := make([]RecentError, 0, len(buf.slots))
+	for i := range buf.slots {
+		if e := buf.slots[i].Load(); e != nil {
+			errs = append(errs, *e)
+		}
+	}
+	slices.SortFunc(errs, func(a, b RecentError) int {
+		switch {
+		case a.Seq > b.Seq:
+			return -1
+		case a.Seq < b.Seq:
+			return 1
+		}
+		return 0
+	})
+
+	return errs
+}
+
+// RecentErrorsHandler returns an http.Handler listing the
+// recorded errors, meant to be served under /debug/errors:
+//
+//	mux.Handle("/debug/errors", errors.RecentErrorsHandler())
+//
+// The errors are listed as an HTML page, or as a JSON array
+// when the client prefers JSON or the format query parameter
+// is "json". They can be filtered by name and status code
+// with the name and status query parameters, the status
+// being a status code (e.g. 404) or class (e.g. 4xx).
+// When the recording is disabled, it responds with a 404.
+func RecentErrorsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if recent.Load() == nil {
+			http.Error(w, "recent errors recording is disabled", http.StatusNotFound)
+			return
+		}
+
+		query := r.URL.Query()
+		name, status := query.Get("name"), query.Get("status")
+
+		errs := RecentErrors()
+		errs = slices.DeleteFunc(errs, func(e RecentError) bool {
+			return (name != "" && e.Name != name) || !matchStatus(status, e.StatusCode)
+		})
+
+		format := query.Get("format")
+		if format == "" && Negotiate(r.Header.Get("Accept"), MediaTypeHTML, MediaTypeJSON) == MediaTypeJSON {
+			format = "json"
+		}
+
+		if format == "json" {
+			b, _ := json.Marshal(errs)
+			w.Header().Set("Content-Type", MediaTypeJSON)
+			_, _ = w.Write(b)
+			return
+		}
+
+		var buf bytes.Buffer
+		if err := recentErrorsTemplate.Execute(&buf, recentErrorsPage{Name: name, Status: status, Errors: errs}); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", MediaTypeHTML+"; charset=utf-8")
+		_, _ = w.Write(buf.Bytes())
+	})
+}
+
+// matchStatus reports whether the status code matches the
+// status filter, an empty filter matches any status code.
+func matchStatus(filter string, statusCode int) bool {
+	if filter == "" {
+		return true
+	}
+
+	if len(filter) == 3 && filter[1:] == "xx" {
+		return filter[:1] == strconv.Itoa(statusCode/100)
+	}
+
+	return filter == strconv.Itoa(statusCode)
+}
+
+type recentErrorsPage struct {
+	Name   string
+	Status string
+	Errors []RecentError
+}
+
+var recentErrorsTemplate = template.Must(template.New("recent").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>/debug/errors</title>
+<style>
+body{font-family:system-ui,sans-serif;margin:2rem;color:#222}
+table{border-collapse:collapse;width:100%}th,td{border-bottom:1px solid #ddd;padding:.4rem;text-align:left;vertical-align:top}
+pre{margin:0;white-space:pre-wrap;word-break:break-all;font-size:.8rem}
+</style>
+</head>
+<body>
+<h1>/debug/errors</h1>
+<form>
+<input name="name" placeholder="name" value="{{.Name}}">
+<input name="status" placeholder="status (e.g. 404 or 5xx)" value="{{.Status}}">
+<button>Filter</button>
+<a href="?format=json{{if .Name}}&amp;name={{.Name}}{{end}}{{if .Status}}&amp;status={{.Status}}{{end}}">JSON</a>
+</form>
+<p>{{len .Errors}} errors</p>
+<table>
+<tr><th>#</th><th>Time</th><th>Request</th><th>Status</th><th>Name</th><th>Error</th></tr>
+{{- range .Errors}}
+<tr>
+<td>{{.Seq}}</td>
+<td>{{.Time.Format "2006-01-02T15:04:05.000Z07:00"}}</td>
+<td>{{.Method}} {{.Path}}{{if .Route}}<br><code>{{.Route}}</code>{{end}}</td>
+<td>{{.StatusCode}}</td>
+<td>{{.Name}}</td>
+<td><pre>{{printf "%s" .Error}}</pre>{{if .Stack}}<details><summary>stack</summary><pre>{{.Stack}}</pre></details>{{end}}</td>
+</tr>
+{{- end}}
+</table>
+</body>
+</html>
+`))
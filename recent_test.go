@@ -0,0 +1,168 @@
+package errors
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// TestRecentErrors is not parallel since it toggles the
+// recording, sequential tests run before the parallel ones.
+func TestRecentErrors(t *testing.T) {
+	defer EnableRecentErrors(0)
+
+	write := func(route, path string, err error) {
+		mux := http.NewServeMux()
+		mux.HandleFunc(route, func(w http.ResponseWriter, r *http.Request) {
+			WriteError(w, r, err)
+		})
+		method := strings.Fields(route)[0]
+		mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(method, path, nil))
+	}
+
+	t.Run("should not record when disabled", func(t *testing.T) {
+		EnableRecentErrors(0)
+		write("GET /", "/", New("boom"))
+
+		if got := RecentErrors(); got != nil {
+			t.Fatalf("expected no errors, got %v", got)
+		}
+
+		rec := httptest.NewRecorder()
+		RecentErrorsHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/errors", nil))
+		if rec.Code != http.StatusNotFound {
+			t.Fatalf("\n got:  %v\n want: %v", rec.Code, http.StatusNotFound)
+		}
+	})
+
+	t.Run("should keep the last errors most recent first", func(t *testing.T) {
+		EnableRecentErrors(2)
+		write("GET /users/{id}", "/users/1", NewNotFoundError("user 1 not found", nil))
+		write("GET /users/{id}", "/users/2", NewNotFoundError("user 2 not found", nil))
+		write("POST /users", "/users", NewWithName("validation_error", "invalid user"))
+
+		errs := RecentErrors()
+		if len(errs) != 2 {
+			t.Fatalf("expected 2 errors, got %d", len(errs))
+		}
+
+		got := errs[0]
+		if got.Seq != 3 || got.Name != "validation_error" || got.StatusCode != 500 ||
+			got.Route != "POST /users" || got.Path != "/users" || got.Method != http.MethodPost {
+			t.Fatalf("unexpected recent error: %+v", got)
+		}
+
+		if want := `{"name":"validation_error","message":"invalid user"}`; string(got.Error) != want {
+			t.Fatalf("\n got:  %s\n want: %v", got.Error, want)
+		}
+
+		if len(got.Stack) == 0 || !strings.Contains(got.Stack[0].Function, "TestRecentErrors") {
+			t.Fatalf("expected the stack of the write call, got:\n%s", got.Stack)
+		}
+
+		if errs[1].Seq != 2 || errs[1].Path != "/users/2" {
+			t.Fatalf("unexpected recent error: %+v", errs[1])
+		}
+	})
+
+	t.Run("should keep the stack of panics", func(t *testing.T) {
+		EnableRecentErrors(1)
+		handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic("boom")
+		}))
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+		got := RecentErrors()[0].Stack
+		if len(got) == 0 || !strings.Contains(got[0].Function, "TestRecentErrors") {
+			t.Fatalf("expected the stack of the panic, got:\n%s", got)
+		}
+	})
+
+	t.Run("should filter by name and status", func(t *testing.T) {
+		EnableRecentErrors(10)
+		write("GET /a", "/a", NewNotFoundError("a", nil))
+		write("GET /b", "/b", NewBadRequestError("b", nil))
+		write("GET /c", "/c", NewServiceUnavailableError("c", nil))
+
+		for query, want := range map[string][]string{
+			"":                      {"/c", "/b", "/a"},
+			"?status=4xx":           {"/b", "/a"},
+			"?status=503":           {"/c"},
+			"?name=not_found_error": {"/a"},
+			"?name=x&status=404":    {},
+		} {
+			rec := httptest.NewRecorder()
+			RecentErrorsHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/errors"+query+sep(query)+"format=json", nil))
+
+			var errs []RecentError
+			if err := json.Unmarshal(rec.Body.Bytes(), &errs); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			got := []string{}
+			for _, e := range errs {
+				got = append(got, e.Path)
+			}
+			if strings.Join(got, ",") != strings.Join(want, ",") {
+				t.Fatalf("%q:\n got:  %v\n want: %v", query, got, want)
+			}
+		}
+	})
+
+	t.Run("should negotiate the HTML and JSON views", func(t *testing.T) {
+		EnableRecentErrors(10)
+		write("GET /html", "/html", NewNotFoundError("<script>", nil))
+
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/debug/errors", nil)
+		req.Header.Set("Accept", "text/html")
+		RecentErrorsHandler().ServeHTTP(rec, req)
+
+		if got := rec.Header().Get("Content-Type"); got != "text/html; charset=utf-8" {
+			t.Fatalf("\n got:  %v\n want: %v", got, "text/html; charset=utf-8")
+		}
+		if body := rec.Body.String(); !strings.Contains(body, "GET /html") || strings.Contains(body, "<script>") {
+			t.Fatalf("unexpected HTML view:\n%s", body)
+		}
+
+		rec = httptest.NewRecorder()
+		req.Header.Set("Accept", "application/json")
+		RecentErrorsHandler().ServeHTTP(rec, req)
+
+		if got := rec.Header().Get("Content-Type"); got != MediaTypeJSON {
+			t.Fatalf("\n got:  %v\n want: %v", got, MediaTypeJSON)
+		}
+	})
+
+	t.Run("should record concurrently", func(t *testing.T) {
+		EnableRecentErrors(16)
+
+		var wg sync.WaitGroup
+		for range 20 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for range 50 {
+					WriteError(httptest.NewRecorder(), nil, New("boom"))
+					_ = RecentErrors()
+				}
+			}()
+		}
+		wg.Wait()
+
+		if got := len(RecentErrors()); got != 16 {
+			t.Fatalf("\n got:  %v\n want: %v", got, 16)
+		}
+	})
+}
+
+func sep(query string) string {
+	if query == "" {
+		return "?"
+	}
+
+	return "&"
+}
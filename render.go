@@ -0,0 +1,144 @@
+package errors
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+)
+
+// maxErrorDepth is the maximum number of nested errors rendered
+// in XML or HTML. It prevents circular references from looping
+// forever.
+const maxErrorDepth = 32
+
+// xmlError is the XML representation of the errors of
+// this package.
+type xmlError struct {
+	XMLName    xml.Name
+	StatusCode int         `xml:"statusCode,omitempty"`
+	Name       string      `xml:"name"`
+	Message    string      `xml:"message"`
+	Cause      *xmlError   `xml:",omitempty"`
+	Details    *xmlDetails `xml:"details,omitempty"`
+}
+
+// xmlDetails is the list of details of an xmlError.
+type xmlDetails struct {
+	Detail []xmlDetail `xml:"detail"`
+}
+
+// xmlDetail is the XML representation of an error detail.
+// Details are rendered as their JSON representation since
+// they may contain maps that are not supported by encoding/xml.
+type xmlDetail struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+// newXMLError builds the XML representation of err using
+// elem as the element name.
+func newXMLError(err error, elem string, depth int) *xmlError {
+	if err == nil {
+		return nil
+	}
+
+	x := &xmlError{XMLName: xml.Name{Local: elem}}
+	var (
+		cause     error
+		causeElem string
+		details   Details
+	)
+
+	switch e := err.(type) {
+	case *HTTPError:
+		x.StatusCode = e.StatusCode
+		x.Name = e.Name
+		x.Message = e.Message
+		cause, causeElem, details = e.Err, "error", e.Details
+	case *GenericError:
+		x.Name = e.Name
+		x.Message = e.Message
+		cause, causeElem, details = e.Original, "original", e.Details
+	case GenericError:
+		return newXMLError(&e, elem, depth)
+	default:
+		x.Name = "error"
+		x.Message = err.Error()
+	}
+
+	if len(details) > 0 {
+		x.Details = &xmlDetails{}
+	}
+
+	for _, d := range details {
+		b, marshalErr := marshalDetail(d)
+		if marshalErr != nil {
+			continue
+		}
+		x.Details.Detail = append(x.Details.Detail, xmlDetail{Type: d.TypeURL(), Value: string(b)})
+	}
+
+	if cause != nil {
+		if depth >= maxErrorDepth {
+			x.Cause = &xmlError{
+				XMLName: xml.Name{Local: causeElem},
+				Name:    "error",
+				Message: "maximum error depth reached, the error may contain a circular reference",
+			}
+		} else {
+			x.Cause = newXMLError(cause, causeElem, depth+1)
+		}
+	}
+
+	return x
+}
+
+// marshalXMLError marshals err as an XML document. It ensures
+// that the returned value is a valid XML document.
+func marshalXMLError(err error) []byte {
+	b, marshalErr := xml.Marshal(newXMLError(err, "error", 0))
+	if marshalErr != nil {
+		b, _ = xml.Marshal(newXMLError(New(marshalErr.Error()), "error", 0))
+	}
+
+	return append([]byte(xml.Header), b...)
+}
+
+// problem is the RFC 9457 problem details representation
+// of an error. The error name and details are added as
+// extension members.
+type problem struct {
+	Type    string  `json:"type"`
+	Title   string  `json:"title"`
+	Status  int     `json:"status"`
+	Detail  string  `json:"detail,omitempty"`
+	Name    string  `json:"name"`
+	Details Details `json:"details,omitempty"`
+}
+
+// ProblemJSON returns the RFC 9457 problem details JSON
+// representation (application/problem+json) of the given
+// error. Errors that are not an HTTPError are rendered as
+// a 500 problem.
+func ProblemJSON(err error) []byte {
+	httpErr := toHTTPError(err)
+	b, marshalErr := json.Marshal(problem{
+		Type:    "about:blank",
+		Title:   http.StatusText(httpErr.StatusCode),
+		Status:  httpErr.StatusCode,
+		Detail:  httpErr.Message,
+		Name:    httpErr.Name,
+		Details: httpErr.Details,
+	})
+	if marshalErr != nil {
+		b, _ = json.Marshal(problem{
+			Type:   "about:blank",
+			Title:  http.StatusText(httpErr.StatusCode),
+			Status: httpErr.StatusCode,
+			Detail: httpErr.Message,
+			Name:   httpErr.Name,
+		})
+	}
+
+	return b
+}
@@ -0,0 +1,110 @@
+package errors
+
+import (
+	"encoding/xml"
+	"errors"
+	"testing"
+)
+
+func TestHTTPError_XML(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should render the error chain as nested elements", func(t *testing.T) {
+		err := NewBadGatewayError("upstream failed", errors.New("timeout <5s>")).(*HTTPError)
+		want := xml.Header + `<error><statusCode>502</statusCode><name>bad_gateway_error</name>` +
+			`<message>upstream failed</message><error><name>error</name><message>timeout &lt;5s&gt;</message></error></error>`
+
+		if got := string(err.XML()); got != want {
+			t.Fatalf("\n got:  %v\n want: %v", got, want)
+		}
+	})
+
+	t.Run("should render details as typed elements", func(t *testing.T) {
+		err := NewNotFoundError("missing", nil).(*HTTPError).WithDetails(LocalizedMessage{Locale: "en", Message: "hi"})
+		want := xml.Header + `<error><statusCode>404</statusCode><name>not_found_error</name><message>missing</message>` +
+			`<details><detail type="type.googleapis.com/google.rpc.LocalizedMessage">` +
+			`{&#34;@type&#34;:&#34;type.googleapis.com/google.rpc.LocalizedMessage&#34;,&#34;locale&#34;:&#34;en&#34;,&#34;message&#34;:&#34;hi&#34;}` +
+			`</detail></details></error>`
+
+		if got := string(err.XML()); got != want {
+			t.Fatalf("\n got:  %v\n want: %v", got, want)
+		}
+	})
+
+	t.Run("should be usable with encoding/xml", func(t *testing.T) {
+		v := struct {
+			XMLName xml.Name  `xml:"response"`
+			Err     HTTPError `xml:"failure"`
+		}{Err: *NewConflictError("conflict", nil).(*HTTPError)}
+
+		want := `<response><failure><statusCode>409</statusCode><name>conflict_error</name><message>conflict</message></failure></response>`
+		b, err := xml.Marshal(v)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if got := string(b); got != want {
+			t.Fatalf("\n got:  %v\n want: %v", got, want)
+		}
+	})
+}
+
+func TestGenericError_XML(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should render the original error", func(t *testing.T) {
+		err := NewWithNameAndErr("name", "message", New("original")).(*GenericError)
+		want := xml.Header + `<error><name>name</name><message>message</message>` +
+			`<original><name>error</name><message>original</message></original></error>`
+
+		if got := string(err.XML()); got != want {
+			t.Fatalf("\n got:  %v\n want: %v", got, want)
+		}
+	})
+
+	t.Run("should stop rendering circular references", func(t *testing.T) {
+		err := &GenericError{Name: "name", Message: "message"}
+		err.Original = err
+
+		var got xmlError
+		if decodeErr := xml.Unmarshal(err.XML(), &got); decodeErr != nil {
+			t.Fatalf("expected valid XML, got %v", decodeErr)
+		}
+	})
+}
+
+func TestText(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should render the http error status and message", func(t *testing.T) {
+		want := "404 not_found_error: user not found"
+		if got := string(NewNotFoundError("user not found", nil).(*HTTPError).Text()); got != want {
+			t.Fatalf("\n got:  %v\n want: %v", got, want)
+		}
+	})
+
+	t.Run("should render the generic error message", func(t *testing.T) {
+		want := "name: message"
+		if got := string(NewWithName("name", "message").(*GenericError).Text()); got != want {
+			t.Fatalf("\n got:  %v\n want: %v", got, want)
+		}
+	})
+}
+
+func TestProblemJSON(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should render an http error as a problem", func(t *testing.T) {
+		want := `{"type":"about:blank","title":"Not Found","status":404,"detail":"user not found","name":"not_found_error"}`
+		if got := string(ProblemJSON(NewNotFoundError("user not found", nil))); got != want {
+			t.Fatalf("\n got:  %v\n want: %v", got, want)
+		}
+	})
+
+	t.Run("should render other errors as a 500 problem", func(t *testing.T) {
+		want := `{"type":"about:blank","title":"Internal Server Error","status":500,"detail":"boom","name":"my_error"}`
+		if got := string(ProblemJSON(NewWithName("my_error", "boom"))); got != want {
+			t.Fatalf("\n got:  %v\n want: %v", got, want)
+		}
+	})
+}
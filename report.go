@@ -0,0 +1,67 @@
+package errors
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+)
+
+// Reporter reports errors, like a Dispatcher does to it's
+// Sink. It must not block.
+type Reporter interface {
+	Report(ctx context.Context, err error) bool
+}
+
+// reporterHolder holds the global Reporter.
+type reporterHolder struct {
+	r Reporter
+}
+
+var reporter atomic.Pointer[reporterHolder]
+
+// SetReporter sets the Reporter of the errors written by
+// WriteError, WriteTwirpError and WriteConnectError and of
+// the panics recovered by Middleware. A nil Reporter disables
+// the reporting, which is disabled by default.
+func SetReporter(r Reporter) {
+	if r == nil {
+		reporter.Store(nil)
+		return
+	}
+
+	reporter.Store(&reporterHolder{r: r})
+}
+
+// Report reports the given error to the Reporter set with
+// SetReporter. It reports whether the error was accepted.
+func Report(ctx context.Context, err error) bool {
+	h := reporter.Load()
+	if h == nil || err == nil {
+		return false
+	}
+
+	return h.r.Report(ctx, err)
+}
+
+// reportWritten reports an error written with the given
+// status code, along with the request method, path and route
+// as context fields. The request is nil when it is not known.
+func reportWritten(r *http.Request, err error, statusCode int) {
+	if reporter.Load() == nil {
+		return
+	}
+
+	ctx := context.Background()
+	fields := map[string]string{"status": strconv.Itoa(statusCode)}
+	if r != nil {
+		ctx = r.Context()
+		fields["method"] = r.Method
+		fields["path"] = r.URL.Path
+		if r.Pattern != "" {
+			fields["route"] = r.Pattern
+		}
+	}
+
+	Report(ContextWithFields(ctx, fields), err)
+}
@@ -0,0 +1,125 @@
+package errors
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// reporterFunc is a Reporter calling the function.
+type reporterFunc func(ctx context.Context, err error) bool
+
+func (f reporterFunc) Report(ctx context.Context, err error) bool {
+	return f(ctx, err)
+}
+
+// TestReport is not parallel since it sets the global
+// reporter, sequential tests run before the parallel ones.
+func TestReport(t *testing.T) {
+	defer SetReporter(nil)
+
+	var (
+		mu      sync.Mutex
+		errs    []error
+		fields  []map[string]string
+		collect = reporterFunc(func(ctx context.Context, err error) bool {
+			mu.Lock()
+			defer mu.Unlock()
+
+			errs = append(errs, err)
+			fields = append(fields, FieldsFromContext(ctx))
+			return true
+		})
+	)
+	reset := func() {
+		mu.Lock()
+		defer mu.Unlock()
+
+		errs, fields = nil, nil
+	}
+
+	t.Run("should not report without a reporter", func(t *testing.T) {
+		SetReporter(nil)
+
+		if Report(context.Background(), New("boom")) {
+			t.Fatalf("expected the error not to be reported")
+		}
+	})
+
+	t.Run("should report the errors written by WriteError", func(t *testing.T) {
+		SetReporter(collect)
+		reset()
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("GET /users/{id}", func(w http.ResponseWriter, r *http.Request) {
+			WriteError(w, r, NewNotFoundError("user not found", nil))
+		})
+		mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/1", nil))
+
+		if len(errs) != 1 || errs[0].Error() != "not_found_error: user not found" {
+			t.Fatalf("unexpected reported errors: %v", errs)
+		}
+
+		want := map[string]string{"status": "404", "method": "GET", "path": "/users/1", "route": "GET /users/{id}"}
+		if !reflect.DeepEqual(fields[0], want) {
+			t.Fatalf("\n got:  %v\n want: %v", fields[0], want)
+		}
+	})
+
+	t.Run("should report the errors written by WriteTwirpError", func(t *testing.T) {
+		SetReporter(collect)
+		reset()
+
+		WriteTwirpError(httptest.NewRecorder(), NewBadRequestError("invalid", nil))
+
+		if len(errs) != 1 || fields[0]["status"] != "400" {
+			t.Fatalf("unexpected reported errors: %v %v", errs, fields)
+		}
+	})
+
+	t.Run("should report the panics of a started response", func(t *testing.T) {
+		SetReporter(collect)
+		reset()
+
+		handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			panic("boom")
+		}))
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+		if len(errs) != 1 || !strings.Contains(errs[0].Error(), "panic: boom") {
+			t.Fatalf("unexpected reported errors: %v", errs)
+		}
+	})
+
+	t.Run("should archive the errors with a rotating file sink", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "errors.jsonl")
+		sink, err := NewRotatingFileSink(RotatingFileConfig{Path: path})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		d := NewDispatcher(sink, DispatcherConfig{})
+		SetReporter(d)
+
+		handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic("boom")
+		}))
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+		if err := d.Close(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		lines := readLines(t, path)
+		if len(lines) != 1 || !strings.Contains(lines[0], `"name":"internal_server_error"`) ||
+			!strings.Contains(lines[0], `"name":"panic"`) || !strings.Contains(lines[0], `"status":"500"`) {
+			t.Fatalf("unexpected archive: %v", lines)
+		}
+	})
+}
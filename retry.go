@@ -0,0 +1,240 @@
+package errors
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"time"
+)
+
+// retryableStatusCodes are the HTTP status codes that are
+// considered transient.
+var retryableStatusCodes = map[int]bool{
+	http.StatusRequestTimeout:     true,
+	http.StatusTooEarly:           true,
+	http.StatusTooManyRequests:    true,
+	http.StatusBadGateway:         true,
+	http.StatusServiceUnavailable: true,
+	http.StatusGatewayTimeout:     true,
+}
+
+// WithRetryable overrides whether the error is retryable
+// and returns it.
+func (e *GenericError) WithRetryable(retryable bool) *GenericError {
+	e.retryable = &retryable
+	return e
+}
+
+// WithRetryable overrides whether the error is retryable
+// and returns it.
+func (e *HTTPError) WithRetryable(retryable bool) *HTTPError {
+	e.retryable = &retryable
+	return e
+}
+
+// Retryable reports whether the given error is transient and
+// the operation that returned it can be retried.
+//
+// The error chain is inspected from the outermost error:
+//   - errors overridden with WithRetryable return the override,
+//   - errors implementing a Retryable() bool method return it,
+//   - HTTP errors are retryable when their status code is 408,
+//     425, 429, 502, 503 or 504 or they have a RetryInfo detail,
+//   - generic errors are retryable when they have a RetryInfo
+//     detail, otherwise their original error is inspected.
+//
+// Context cancellation errors are never retryable.
+func Retryable(err error) bool {
+	if err == nil ||
+		stderrors.Is(err, context.Canceled) ||
+		stderrors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	for depth := 0; err != nil && depth < maxErrorDepth; depth++ {
+		switch e := err.(type) {
+		case *HTTPError:
+			if e.retryable != nil {
+				return *e.retryable
+			}
+
+			return retryableStatusCodes[e.StatusCode] || hasRetryInfo(e.Details)
+		case *GenericError:
+			if e.retryable != nil {
+				return *e.retryable
+			}
+
+			if hasRetryInfo(e.Details) {
+				return true
+			}
+		case interface{ Retryable() bool }:
+			return e.Retryable()
+		}
+
+		err = unwrapOnce(err)
+	}
+
+	return false
+}
+
+func hasRetryInfo(details Details) bool {
+	for _, d := range details {
+		if _, ok := d.(RetryInfo); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// retryAfter returns the delay requested by the error chain
+// through a Retry-After header or a RetryInfo detail.
+func retryAfter(err error) (time.Duration, bool) {
+	for depth := 0; err != nil && depth < maxErrorDepth; depth++ {
+		if e, ok := err.(*HTTPError); ok {
+			if d, ok := e.RetryAfter(); ok {
+				return d, true
+			}
+		}
+
+		for _, d := range detailsOf(err) {
+			if info, ok := d.(RetryInfo); ok {
+				return info.RetryDelay, true
+			}
+		}
+
+		err = unwrapOnce(err)
+	}
+
+	return 0, false
+}
+
+// RetryPolicy configures the attempts and the exponential
+// backoff of Retry. Zero values use the DefaultRetryPolicy
+// ones, except for Jitter.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of calls.
+	MaxAttempts int
+
+	// InitialDelay is the delay before the second attempt.
+	InitialDelay time.Duration
+
+	// MaxDelay caps the backoff delay. A longer Retry-After
+	// requested by the error is still honoured.
+	MaxDelay time.Duration
+
+	// Multiplier is the growth factor of the delay.
+	Multiplier float64
+
+	// Jitter randomizes the delay by up to the given
+	// fraction (between 0 and 1) in both directions.
+	Jitter float64
+
+	// Retryable classifies the errors, defaults to Retryable.
+	Retryable func(error) bool
+}
+
+// DefaultRetryPolicy is the policy used for the zero values
+// of a RetryPolicy.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:  3,
+	InitialDelay: 100 * time.Millisecond,
+	MaxDelay:     30 * time.Second,
+	Multiplier:   2,
+	Jitter:       0.2,
+	Retryable:    Retryable,
+}
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = DefaultRetryPolicy.MaxAttempts
+	}
+	if p.InitialDelay <= 0 {
+		p.InitialDelay = DefaultRetryPolicy.InitialDelay
+	}
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = DefaultRetryPolicy.MaxDelay
+	}
+	if p.Multiplier < 1 {
+		p.Multiplier = DefaultRetryPolicy.Multiplier
+	}
+	if p.Retryable == nil {
+		p.Retryable = DefaultRetryPolicy.Retryable
+	}
+	p.Jitter = math.Min(math.Max(p.Jitter, 0), 1)
+
+	return p
+}
+
+// backoff returns the delay before the given attempt (starting
+// at 1 for the delay after the first failure).
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := float64(p.InitialDelay) * math.Pow(p.Multiplier, float64(attempt-1))
+	d = math.Min(d, float64(p.MaxDelay))
+
+	if p.Jitter > 0 {
+		d *= 1 + p.Jitter*(2*rand.Float64()-1)
+	}
+
+	return time.Duration(d)
+}
+
+// Retry calls fn until it succeeds, returns an error that is
+// not retryable, the attempts are exhausted or ctx is done.
+//
+// Attempts are delayed with an exponential backoff with jitter.
+// When the error requests a longer delay with a Retry-After
+// header or a RetryInfo detail, that delay is used instead. If
+// the delay would exceed the ctx deadline, Retry stops early.
+//
+// The returned error is a MultiError named "retry_error" with
+// every failed attempt as a cause.
+func Retry(ctx context.Context, policy RetryPolicy, fn func(ctx context.Context) error) error {
+	policy = policy.withDefaults()
+	retryErr := &MultiError{Name: "retry_error"}
+
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil {
+			return nil
+		}
+
+		retryErr.Causes = append(retryErr.Causes, Cause{
+			Label: fmt.Sprintf("attempt %d", attempt),
+			Err:   err,
+		})
+
+		if !policy.Retryable(err) {
+			retryErr.Message = fmt.Sprintf("attempt %d failed with a non-retryable error", attempt)
+			return retryErr
+		}
+
+		if attempt >= policy.MaxAttempts {
+			retryErr.Message = fmt.Sprintf("all %d attempts failed", attempt)
+			return retryErr
+		}
+
+		delay := policy.backoff(attempt)
+		if d, ok := retryAfter(err); ok && d > delay {
+			delay = d
+		}
+
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			retryErr.Message = fmt.Sprintf("retry delay of %s exceeds the context deadline after %d attempts", delay, attempt)
+			return retryErr
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			retryErr.Message = fmt.Sprintf("context done after %d attempts", attempt)
+			retryErr.Causes = append(retryErr.Causes, Cause{Label: "context", Err: ctx.Err()})
+			return retryErr
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package errors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+type retryableError struct{ retryable bool }
+
+func (e retryableError) Error() string   { return "retryable error" }
+func (e retryableError) Retryable() bool { return e.retryable }
+
+func TestRetryable(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"service unavailable", NewServiceUnavailableError("message", nil), true},
+		{"too many requests", NewTooManyRequestsError("message", nil), true},
+		{"gateway timeout", NewGatewayTimeoutError("message", nil), true},
+		{"bad request", NewBadRequestError("message", nil), false},
+		{"internal server error", NewInternalServerError("message", nil), false},
+		{"overridden http error", NewInternalServerError("message", nil).(*HTTPError).WithRetryable(true), true},
+		{"overridden unavailable", NewServiceUnavailableError("message", nil).(*HTTPError).WithRetryable(false), false},
+		{"http error with retry info", NewConflictError("message", nil).(*HTTPError).WithDetails(RetryInfo{}), true},
+		{"generic error", New("message"), false},
+		{"overridden generic error", New("message").(*GenericError).WithRetryable(true), true},
+		{"generic error wrapping retryable", NewWithNameAndErr("name", "message", NewBadGatewayError("message", nil)), true},
+		{"fmt wrapping retryable", fmt.Errorf("wrapped: %w", NewBadGatewayError("message", nil)), true},
+		{"custom retryable", retryableError{retryable: true}, true},
+		{"custom not retryable", retryableError{retryable: false}, false},
+		{"context canceled", fmt.Errorf("wrapped: %w", context.Canceled), false},
+		{"plain error", errors.New("message"), false},
+	}
+
+	for _, tt := range tests {
+		if got := Retryable(tt.err); got != tt.want {
+			t.Errorf("Retryable(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRetry(t *testing.T) {
+	t.Parallel()
+
+	policy := RetryPolicy{MaxAttempts: 3, InitialDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+
+	t.Run("should return nil once fn succeeds", func(t *testing.T) {
+		calls := 0
+		err := Retry(context.Background(), policy, func(ctx context.Context) error {
+			calls++
+			if calls < 3 {
+				return NewServiceUnavailableError("unavailable", nil)
+			}
+			return nil
+		})
+
+		if err != nil || calls != 3 {
+			t.Fatalf("expected success after 3 calls, got %d calls and %v", calls, err)
+		}
+	})
+
+	t.Run("should list every failed attempt", func(t *testing.T) {
+		calls := 0
+		err := Retry(context.Background(), policy, func(ctx context.Context) error {
+			calls++
+			return NewBadGatewayError(fmt.Sprintf("failure %d", calls), nil)
+		})
+
+		retryErr, ok := err.(*MultiError)
+		if !ok {
+			t.Fatalf("expected error to be of type MultiError, got %T", err)
+		}
+
+		if retryErr.Name != "retry_error" || retryErr.Message != "all 3 attempts failed" || len(retryErr.Causes) != 3 {
+			t.Fatalf("unexpected retry error: %s", retryErr.JSON())
+		}
+
+		if retryErr.Causes[2].Label != "attempt 3" {
+			t.Fatalf("\n got:  %v\n want: %v", retryErr.Causes[2].Label, "attempt 3")
+		}
+	})
+
+	t.Run("should stop on non-retryable errors", func(t *testing.T) {
+		calls := 0
+		err := Retry(context.Background(), policy, func(ctx context.Context) error {
+			calls++
+			return NewBadRequestError("invalid", nil)
+		})
+
+		if calls != 1 {
+			t.Fatalf("expected 1 call, got %d", calls)
+		}
+
+		if got := err.(*MultiError).Message; got != "attempt 1 failed with a non-retryable error" {
+			t.Fatalf("\n got:  %v\n want: %v", got, "attempt 1 failed with a non-retryable error")
+		}
+	})
+
+	t.Run("should honour the delay requested by the error", func(t *testing.T) {
+		start := time.Now()
+		calls := 0
+		_ = Retry(context.Background(), RetryPolicy{MaxAttempts: 2, InitialDelay: time.Millisecond}, func(ctx context.Context) error {
+			calls++
+			return NewTooManyRequestsError("slow down", nil).(*HTTPError).WithDetails(RetryInfo{RetryDelay: 50 * time.Millisecond})
+		})
+
+		if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+			t.Fatalf("expected to wait at least 50ms, waited %s", elapsed)
+		}
+	})
+
+	t.Run("should stop when Retry-After exceeds the context deadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		err := Retry(ctx, policy, func(ctx context.Context) error {
+			return NewServiceUnavailableError("unavailable", nil).(*HTTPError).WithRetryAfter(time.Minute)
+		})
+
+		if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+			t.Fatalf("expected to stop early, waited %s", elapsed)
+		}
+
+		if len(err.(*MultiError).Causes) != 1 {
+			t.Fatalf("expected 1 attempt, got %s", err.(Error).JSON())
+		}
+	})
+
+	t.Run("should stop when the context is canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		err := Retry(ctx, RetryPolicy{MaxAttempts: 5, InitialDelay: time.Second}, func(ctx context.Context) error {
+			cancel()
+			return NewServiceUnavailableError("unavailable", nil)
+		})
+
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected a context.Canceled cause, got %v", err)
+		}
+	})
+}
+
+func TestRetryPolicy_backoff(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should grow exponentially up to the max delay", func(t *testing.T) {
+		p := RetryPolicy{InitialDelay: 10 * time.Millisecond, MaxDelay: 50 * time.Millisecond, Multiplier: 2}.withDefaults()
+
+		want := []time.Duration{10, 20, 40, 50, 50}
+		for i, w := range want {
+			if got := p.backoff(i + 1); got != w*time.Millisecond {
+				t.Fatalf("backoff(%d) = %s, want %s", i+1, got, w*time.Millisecond)
+			}
+		}
+	})
+
+	t.Run("should apply the jitter", func(t *testing.T) {
+		p := RetryPolicy{InitialDelay: 100 * time.Millisecond, Jitter: 0.5}.withDefaults()
+
+		for i := 0; i < 100; i++ {
+			if got := p.backoff(1); got < 50*time.Millisecond || got > 150*time.Millisecond {
+				t.Fatalf("backoff(1) = %s, want between 50ms and 150ms", got)
+			}
+		}
+	})
+}
@@ -0,0 +1,157 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"slices"
+	"strings"
+	"sync/atomic"
+)
+
+// returnTraceEnabled reports whether the wrap sites are
+// recorded in the return trace.
+var returnTraceEnabled atomic.Bool
+
+// EnableReturnTrace enables or disables the recording of the
+// return trace, which is disabled by default.
+//
+// When enabled, Wrap, NewWithNameAndErr and Op.Wrap record the
+// location they are called from, a single program counter per
+// call. Errors that already are a GenericError or an HTTPError
+// are copied by Wrap to record the location, the copy still
+// matches the original error with errors.Is.
+func EnableReturnTrace(enabled bool) {
+	returnTraceEnabled.Store(enabled)
+}
+
+// returnPC returns the program counter of the caller of the
+// function calling returnPC, or 0 when the return trace is
+// disabled.
+func returnPC() uintptr {
+	if !returnTraceEnabled.Load() {
+		return 0
+	}
+
+	var pc [1]uintptr
+	if runtime.Callers(3, pc[:]) == 0 {
+		return 0
+	}
+
+	return pc[0]
+}
+
+// appendPC returns a new slice with the program counter
+// appended, or the given one when the program counter is 0.
+func appendPC(pcs []uintptr, pc uintptr) []uintptr {
+	if pc == 0 {
+		return pcs
+	}
+
+	return append(pcs[:len(pcs):len(pcs)], pc)
+}
+
+// annotate returns a copy of the given GenericError or
+// HTTPError with the operation prepended to it's operation
+// trail and the program counter appended to it's return trace.
+// The slices of the copy are clipped and it's header cloned so
+// that modifying it leaves the original error unchanged.
+// The returned bool is false for any other error.
+func annotate(err error, op Op, pc uintptr) (error, bool) {
+	switch e := err.(type) {
+	case *GenericError:
+		c := *e
+		c.source = e
+		c.Details = slices.Clip(c.Details)
+		annotateFields(&c.Ops, &c.returns, op, pc)
+		return &c, true
+	case GenericError:
+		e.Details = slices.Clip(e.Details)
+		annotateFields(&e.Ops, &e.returns, op, pc)
+		return e, true
+	case *HTTPError:
+		c := *e
+		c.source = e
+		c.Details = slices.Clip(c.Details)
+		c.Header = c.Header.Clone()
+		annotateFields(&c.Ops, &c.returns, op, pc)
+		return &c, true
+	}
+
+	return err, false
+}
+
+func annotateFields(ops *[]string, returns *[]uintptr, op Op, pc uintptr) {
+	*ops = slices.Clip(*ops)
+	if op != "" {
+		*ops = append([]string{string(op)}, *ops...)
+	}
+	*returns = appendPC(*returns, pc)
+}
+
+// ReturnTrace returns the locations where the error chain was
+// wrapped or returned with Wrap, NewWithNameAndErr or Op.Wrap,
+// the first one being the innermost. It is empty unless the
+// return trace is enabled with EnableReturnTrace.
+func ReturnTrace(err error) Stack {
+	var levels [][]uintptr
+	for depth := 0; err != nil && depth < maxErrorDepth; depth++ {
+		switch e := err.(type) {
+		case *GenericError:
+			levels = append(levels, e.returns)
+		case GenericError:
+			levels = append(levels, e.returns)
+		case *HTTPError:
+			levels = append(levels, e.returns)
+		}
+
+		err = unwrapOnce(err)
+	}
+
+	var trace Stack
+	for i := len(levels) - 1; i >= 0; i-- {
+		trace = append(trace, framesOfPCs(levels[i])...)
+	}
+
+	return trace
+}
+
+// framesOfPCs resolves program counters that were recorded
+// independently of each other, unlike the ones of a stack.
+func framesOfPCs(pcs []uintptr) Stack {
+	if len(pcs) == 0 {
+		return nil
+	}
+
+	stack := make(Stack, 0, len(pcs))
+	for _, pc := range pcs {
+		stack = append(stack, framesOf([]uintptr{pc})[0])
+	}
+
+	return stack
+}
+
+// formatError implements fmt.Formatter for the errors of
+// this package. The %+v verb prints the return trace after
+// the error message, one location per line, and the %#v verb
+// prints the Go-syntax representation of goSyntax, a
+// conversion of err to a type without the Format method.
+// The other verbs format the error message.
+func formatError(s fmt.State, verb rune, err error, goSyntax any) {
+	switch {
+	case verb == 'v' && s.Flag('+'):
+		_, _ = io.WriteString(s, err.Error())
+		for _, f := range ReturnTrace(err) {
+			_, _ = fmt.Fprintf(s, "\n\t%s", f)
+		}
+	case verb == 'v' && s.Flag('#'):
+		repr := fmt.Sprintf("%#v", goSyntax)
+		from := strings.TrimPrefix(fmt.Sprintf("%T", goSyntax), "*")
+		to := strings.TrimPrefix(fmt.Sprintf("%T", err), "*")
+		_, _ = io.WriteString(s, strings.Replace(repr, from, to, 1))
+	case verb == 'v', verb == 's', verb == 'q', verb == 'x', verb == 'X':
+		_, _ = fmt.Fprintf(s, fmt.FormatString(s, verb), err.Error())
+	default:
+		_, _ = fmt.Fprintf(s, "%%!%c(%T=%s)", verb, err, err.Error())
+	}
+}
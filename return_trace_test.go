@@ -0,0 +1,124 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// TestReturnTrace is not parallel since it toggles the return
+// trace, sequential tests run before the parallel ones.
+func TestReturnTrace(t *testing.T) {
+	EnableReturnTrace(true)
+	defer EnableReturnTrace(false)
+
+	line := func() int {
+		_, _, l, _ := runtime.Caller(1)
+		return l
+	}
+
+	t.Run("should record every wrap site innermost first", func(t *testing.T) {
+		const op Op = "users.Get"
+
+		sentinel := New("not found")
+		err, l1 := Wrap(sentinel), line()
+		err, l2 := op.Wrap(err), line()
+		err, l3 := NewWithNameAndErr("lookup_error", "lookup failed", err), line()
+
+		trace := ReturnTrace(err)
+		if len(trace) != 3 {
+			t.Fatalf("expected 3 locations, got:\n%s", trace)
+		}
+
+		for i, want := range []int{l1, l2, l3} {
+			if !strings.HasSuffix(trace[i].File, "return_trace_test.go") || trace[i].Line != want {
+				t.Fatalf("\n got:  %v\n want: line %d", trace[i], want)
+			}
+		}
+	})
+
+	t.Run("should keep the identity of the wrapped error", func(t *testing.T) {
+		sentinel := NewNotFoundError("not found", nil)
+		err := Op("repo.Find").Wrap(Wrap(sentinel))
+
+		if err == sentinel {
+			t.Fatalf("expected a copy of the error")
+		}
+
+		if !errors.Is(err, sentinel) {
+			t.Fatalf("expected the copy to match the wrapped error")
+		}
+
+		if len(sentinel.(*HTTPError).returns) != 0 {
+			t.Fatalf("expected the wrapped error to be unchanged")
+		}
+	})
+
+	t.Run("should render the trace in JSON", func(t *testing.T) {
+		err := Wrap(errors.New("boom")).(Error)
+
+		if !strings.Contains(string(err.JSON()), `"returnTrace":[{"function":"github.com/iolave/go-errors.TestReturnTrace`) {
+			t.Fatalf("expected the return trace in the JSON, got %s", err.JSON())
+		}
+	})
+
+	t.Run("should print the trace with %+v", func(t *testing.T) {
+		err := Wrap(NewBadRequestError("invalid", nil))
+
+		lines := strings.Split(fmt.Sprintf("%+v", err), "\n")
+		if len(lines) != 2 || lines[0] != "bad_request_error: invalid" ||
+			!strings.Contains(lines[1], "return_trace_test.go") {
+			t.Fatalf("unexpected output:\n%s", strings.Join(lines, "\n"))
+		}
+
+		got := fmt.Sprintf("%v", err)
+		want := "bad_request_error: invalid"
+		if got != want {
+			t.Fatalf("\n got:  %v\n want: %v", got, want)
+		}
+	})
+
+	t.Run("should not record when disabled", func(t *testing.T) {
+		EnableReturnTrace(false)
+		defer EnableReturnTrace(true)
+
+		sentinel := New("boom")
+		if err := Wrap(sentinel); err != sentinel {
+			t.Fatalf("expected the error to be returned as is")
+		}
+
+		if trace := ReturnTrace(NewWithNameAndErr("error", "boom", sentinel)); len(trace) != 0 {
+			t.Fatalf("expected an empty trace, got:\n%s", trace)
+		}
+	})
+}
+
+func TestFormatError(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should format the message with the string verbs", func(t *testing.T) {
+		for _, err := range []error{New("boom"), NewNotFoundError("not found", nil)} {
+			for _, verb := range []string{"%v", "%s", "%q", "%x", "%X", "%20s"} {
+				got := fmt.Sprintf(verb, err)
+				want := fmt.Sprintf(verb, err.Error())
+				if got != want {
+					t.Fatalf("%s:\n got:  %v\n want: %v", verb, got, want)
+				}
+			}
+		}
+	})
+
+	t.Run("should format the Go-syntax representation with %#v", func(t *testing.T) {
+		got := fmt.Sprintf("%#v", New("boom"))
+		if want := `errors.GenericError{Name:"error", Message:"boom", `; !strings.HasPrefix(got, want) {
+			t.Fatalf("\n got:  %v\n want: %v", got, want)
+		}
+
+		got = fmt.Sprintf("%#v", NewNotFoundError("not found", nil))
+		if want := `&errors.HTTPError{StatusCode:404, Name:"not_found_error", `; !strings.HasPrefix(got, want) {
+			t.Fatalf("\n got:  %v\n want: %v", got, want)
+		}
+	})
+}
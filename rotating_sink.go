@@ -0,0 +1,312 @@
+package errors
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RotatingFileConfig configures a RotatingFileSink. Zero
+// values use the documented defaults.
+type RotatingFileConfig struct {
+	// Path is the path of the file the events are appended to.
+	Path string
+
+	// MaxSize is the size in bytes above which the file is
+	// rotated. Defaults to 100 MiB.
+	MaxSize int64
+
+	// MaxAge is the age above which the file is rotated. The
+	// age of an existing file counts from the last rotation,
+	// or from it's last write when it was never rotated.
+	// Files are not rotated by age by default.
+	MaxAge time.Duration
+
+	// MaxBackups is the number of rotated files kept, the
+	// oldest ones are removed. All of them are kept by default.
+	MaxBackups int
+
+	// Compress compresses the rotated files with gzip.
+	Compress bool
+}
+
+// RotatingFileSink appends the events to a file as JSON lines,
+// rotating it by size and age. It is safe for concurrent use.
+//
+// Rotated files are renamed with the time of the rotation
+// inserted before the extension of the file, like
+// errors-20240102T030405.000000000.jsonl for errors.jsonl.
+type RotatingFileSink struct {
+	cfg RotatingFileConfig
+	now func() time.Time
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	openedAt time.Time
+	rotated  time.Time
+}
+
+// NewRotatingFileSink opens or creates the file of the given
+// config and returns a RotatingFileSink appending to it.
+func NewRotatingFileSink(cfg RotatingFileConfig) (*RotatingFileSink, error) {
+	if cfg.Path == "" {
+		return nil, New("rotating file sink: missing path")
+	}
+	if cfg.MaxSize <= 0 {
+		cfg.MaxSize = 100 << 20
+	}
+
+	s := &RotatingFileSink{cfg: cfg, now: time.Now}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Write appends every event on it's own line, rotating the
+// file before a line that would exceed the maximum size or
+// when the file is older than the maximum age.
+func (s *RotatingFileSink) Write(_ context.Context, events []Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return New("rotating file sink: closed")
+	}
+
+	for _, e := range events {
+		line, err := marshalJSONL([]Event{e})
+		if err != nil {
+			return err
+		}
+
+		if s.shouldRotate(int64(len(line))) {
+			if err := s.rotate(); err != nil {
+				return err
+			}
+		}
+
+		n, err := s.file.Write(line)
+		s.size += int64(n)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Flush syncs the file.
+func (s *RotatingFileSink) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return nil
+	}
+
+	return s.file.Sync()
+}
+
+// Close closes the file.
+func (s *RotatingFileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return nil
+	}
+
+	err := s.file.Close()
+	s.file = nil
+
+	return err
+}
+
+func (s *RotatingFileSink) shouldRotate(n int64) bool {
+	if s.size > 0 && s.size+n > s.cfg.MaxSize {
+		return true
+	}
+
+	return s.cfg.MaxAge > 0 && s.now().Sub(s.openedAt) >= s.cfg.MaxAge
+}
+
+func (s *RotatingFileSink) open() error {
+	file, err := os.OpenFile(s.cfg.Path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	s.file = file
+	s.size = info.Size()
+	s.openedAt = s.now()
+
+	// an existing file was started by the last rotation, or
+	// last written when it was never rotated
+	if s.size > 0 {
+		s.openedAt = info.ModTime()
+		if backups, err := s.Backups(); err == nil && len(backups) > 0 {
+			_, prefix, ext := s.backupParts()
+			s.openedAt, _ = backupTime(filepath.Base(backups[len(backups)-1]), prefix, ext)
+		}
+	}
+
+	return nil
+}
+
+// rotate renames the current file, opens a new one and
+// applies the compression and retention of the rotated files.
+func (s *RotatingFileSink) rotate() error {
+	if err := s.file.Close(); err != nil {
+		return err
+	}
+
+	// the rotation times are kept increasing so the names
+	// of the rotated files are unique and sorted
+	at := s.now()
+	if !at.After(s.rotated) {
+		at = s.rotated.Add(time.Nanosecond)
+	}
+	s.rotated = at
+
+	dir, prefix, ext := s.backupParts()
+	backup := filepath.Join(dir, prefix+at.UTC().Format(backupTimeFormat)+ext)
+	if err := os.Rename(s.cfg.Path, backup); err != nil {
+		if openErr := s.open(); openErr != nil {
+			s.file = nil
+		}
+		return err
+	}
+
+	if err := s.open(); err != nil {
+		return err
+	}
+
+	if s.cfg.Compress {
+		if err := compressFile(backup); err != nil {
+			return err
+		}
+	}
+
+	return s.removeOldBackups()
+}
+
+// backupTimeFormat is the format of the rotation time in
+// the names of the rotated files.
+const backupTimeFormat = "20060102T150405.000000000"
+
+// backupParts returns the directory, the name prefix and the
+// extension of the rotated files.
+func (s *RotatingFileSink) backupParts() (dir, prefix, ext string) {
+	dir, name := filepath.Split(s.cfg.Path)
+	ext = filepath.Ext(name)
+
+	return dir, strings.TrimSuffix(name, ext) + "-", ext
+}
+
+// Backups returns the paths of the rotated files, the
+// oldest first. The other files of the directory sharing the
+// name prefix are ignored.
+func (s *RotatingFileSink) Backups() ([]string, error) {
+	dir, prefix, ext := s.backupParts()
+	if dir == "" {
+		dir = "."
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []string
+	for _, entry := range entries {
+		if _, ok := backupTime(entry.Name(), prefix, ext); ok && !entry.IsDir() {
+			backups = append(backups, filepath.Join(dir, entry.Name()))
+		}
+	}
+	slices.Sort(backups)
+
+	return backups, nil
+}
+
+// backupTime returns the rotation time of the rotated file
+// with the given name, it reports whether the name is the one
+// of a rotated file.
+func backupTime(name, prefix, ext string) (time.Time, bool) {
+	ts, ok := strings.CutPrefix(name, prefix)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	ts, ok = strings.CutSuffix(strings.TrimSuffix(ts, ".gz"), ext)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	t, err := time.Parse(backupTimeFormat, ts)
+	return t, err == nil
+}
+
+func (s *RotatingFileSink) removeOldBackups() error {
+	if s.cfg.MaxBackups <= 0 {
+		return nil
+	}
+
+	backups, err := s.Backups()
+	if err != nil {
+		return err
+	}
+
+	for len(backups) > s.cfg.MaxBackups {
+		if err := os.Remove(backups[0]); err != nil {
+			return err
+		}
+		backups = backups[1:]
+	}
+
+	return nil
+}
+
+// compressFile replaces the file at the given path with
+// a gzip compressed copy with the .gz extension.
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+".gz", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(dst)
+	if _, err := io.Copy(gz, src); err != nil {
+		_ = dst.Close()
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		_ = dst.Close()
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+
+	return os.Remove(path)
+}
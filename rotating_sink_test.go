@@ -0,0 +1,265 @@
+package errors
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestRotatingFileSink returns a sink in a temporary
+// directory and a pointer to it's clock.
+func newTestRotatingFileSink(t *testing.T, cfg RotatingFileConfig) (*RotatingFileSink, *time.Time) {
+	t.Helper()
+
+	cfg.Path = filepath.Join(t.TempDir(), "errors.jsonl")
+	s, err := NewRotatingFileSink(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	s.openedAt = now
+
+	return s, &now
+}
+
+func lineSize(t *testing.T) int64 {
+	t.Helper()
+
+	b, err := marshalJSONL(testEvents(1))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	return int64(len(b))
+}
+
+func readLines(t *testing.T, path string) []string {
+	t.Helper()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		r = gz
+	}
+
+	b, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	return strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")
+}
+
+func TestRotatingFileSink(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("should rotate by size", func(t *testing.T) {
+		s, _ := newTestRotatingFileSink(t, RotatingFileConfig{MaxSize: 2 * lineSize(t)})
+
+		if err := s.Write(ctx, testEvents(5)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		backups, err := s.Backups()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(backups) != 2 {
+			t.Fatalf("expected 2 rotated files, got %v", backups)
+		}
+
+		if !strings.HasSuffix(backups[0], "errors-20240102T030405.000000000.jsonl") {
+			t.Fatalf("unexpected rotated file name %s", backups[0])
+		}
+
+		for _, path := range backups {
+			if lines := readLines(t, path); len(lines) != 2 {
+				t.Fatalf("expected 2 lines in %s, got %d", path, len(lines))
+			}
+		}
+
+		if lines := readLines(t, s.cfg.Path); len(lines) != 1 || !strings.Contains(lines[0], `"i":"e"`) {
+			t.Fatalf("unexpected current file: %v", lines)
+		}
+	})
+
+	t.Run("should rotate by age", func(t *testing.T) {
+		s, now := newTestRotatingFileSink(t, RotatingFileConfig{MaxAge: time.Minute})
+
+		for _, d := range []time.Duration{0, 30 * time.Second, 40 * time.Second} {
+			*now = now.Add(d)
+			if err := s.Write(ctx, testEvents(1)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+
+		backups, _ := s.Backups()
+		if len(backups) != 1 {
+			t.Fatalf("expected 1 rotated file, got %v", backups)
+		}
+
+		if lines := readLines(t, backups[0]); len(lines) != 2 {
+			t.Fatalf("expected 2 lines, got %d", len(lines))
+		}
+	})
+
+	t.Run("should keep the configured number of backups", func(t *testing.T) {
+		s, now := newTestRotatingFileSink(t, RotatingFileConfig{MaxSize: 1, MaxBackups: 2})
+
+		// the rotated files are named after the rotation time
+		for _, e := range testEvents(5) {
+			if err := s.Write(ctx, []Event{e}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			*now = now.Add(time.Second)
+		}
+
+		backups, _ := s.Backups()
+		if len(backups) != 2 {
+			t.Fatalf("expected 2 rotated files, got %v", backups)
+		}
+
+		if lines := readLines(t, backups[0]); !strings.Contains(lines[0], `"i":"c"`) {
+			t.Fatalf("expected the oldest files to be removed, got %v", lines)
+		}
+	})
+
+	t.Run("should not remove the other files of the directory", func(t *testing.T) {
+		s, now := newTestRotatingFileSink(t, RotatingFileConfig{MaxSize: 1, MaxBackups: 1})
+
+		dir := filepath.Dir(s.cfg.Path)
+		others := []string{"errors-http.jsonl", "errors-20240102.jsonl", "errors-http.jsonl.gz"}
+		for _, name := range others {
+			if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+
+		for _, e := range testEvents(3) {
+			if err := s.Write(ctx, []Event{e}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			*now = now.Add(time.Second)
+		}
+
+		if backups, _ := s.Backups(); len(backups) != 1 {
+			t.Fatalf("expected 1 rotated file, got %v", backups)
+		}
+
+		for _, name := range others {
+			if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+				t.Fatalf("expected %s to be kept: %v", name, err)
+			}
+		}
+	})
+
+	t.Run("should compress the rotated files", func(t *testing.T) {
+		s, _ := newTestRotatingFileSink(t, RotatingFileConfig{MaxSize: 1, Compress: true})
+
+		if err := s.Write(ctx, testEvents(2)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		backups, _ := s.Backups()
+		if len(backups) != 1 || !strings.HasSuffix(backups[0], ".jsonl.gz") {
+			t.Fatalf("expected 1 compressed file, got %v", backups)
+		}
+
+		if lines := readLines(t, backups[0]); len(lines) != 1 || !strings.Contains(lines[0], `"i":"a"`) {
+			t.Fatalf("unexpected compressed file: %v", lines)
+		}
+	})
+
+	t.Run("should append to an existing file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "errors.jsonl")
+		if err := os.WriteFile(path, []byte("existing\n"), 0o644); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		s, err := NewRotatingFileSink(RotatingFileConfig{Path: path})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if err := s.Write(ctx, testEvents(1)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := s.Close(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if lines := readLines(t, path); len(lines) != 2 || lines[0] != "existing" {
+			t.Fatalf("unexpected file: %v", lines)
+		}
+
+		if err := s.Write(ctx, testEvents(1)); err == nil {
+			t.Fatalf("expected an error once closed")
+		}
+	})
+
+	t.Run("should rotate an existing file by it's age", func(t *testing.T) {
+		tests := map[string]func(t *testing.T, path string){
+			"last written": func(t *testing.T, path string) {
+				old := time.Now().Add(-2 * time.Hour)
+				if err := os.Chtimes(path, old, old); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			},
+			"last rotated": func(t *testing.T, path string) {
+				at := time.Now().Add(-2 * time.Hour).UTC().Format(backupTimeFormat)
+				backup := filepath.Join(filepath.Dir(path), "errors-"+at+".jsonl")
+				if err := os.WriteFile(backup, []byte("rotated\n"), 0o644); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			},
+		}
+
+		for name, age := range tests {
+			path := filepath.Join(t.TempDir(), "errors.jsonl")
+			if err := os.WriteFile(path, []byte("existing\n"), 0o644); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			age(t, path)
+
+			s, err := NewRotatingFileSink(RotatingFileConfig{Path: path, MaxAge: time.Hour})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer s.Close()
+
+			if err := s.Write(ctx, testEvents(1)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if lines := readLines(t, path); len(lines) != 1 || lines[0] == "existing" {
+				t.Fatalf("%s: expected the file to be rotated, got %v", name, lines)
+			}
+		}
+	})
+
+	t.Run("should require a path", func(t *testing.T) {
+		if _, err := NewRotatingFileSink(RotatingFileConfig{}); err == nil {
+			t.Fatalf("expected an error")
+		}
+	})
+}
@@ -0,0 +1,195 @@
+package errors
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"maps"
+	"os"
+	"sync"
+	"time"
+)
+
+// Sink receives the errors reported through a Dispatcher
+// in batches. Sinks implementing the Flusher interface or
+// io.Closer are flushed and closed by the Dispatcher.
+//
+// The events slice must not be retained after Write returns.
+type Sink interface {
+	Write(ctx context.Context, events []Event) error
+}
+
+// Flusher is implemented by the sinks buffering their writes.
+type Flusher interface {
+	Flush() error
+}
+
+// Event is an error reported to a Sink along with the time
+// it was reported at and the fields of it's context.
+type Event struct {
+	Time   time.Time
+	Err    Error
+	Fields map[string]string
+}
+
+// NewEvent creates a new Event reported now, with the fields
+// of the given context. Errors that are not an Error are
+// wrapped first, the Err of the event is nil for a nil error.
+func NewEvent(ctx context.Context, err error) Event {
+	e, _ := wrap(err).(Error)
+
+	return Event{
+		Time:   time.Now(),
+		Err:    e,
+		Fields: FieldsFromContext(ctx),
+	}
+}
+
+// MarshalJSON implements the json.Marshaler interface, the
+// error is rendered with it's JSON() method.
+func (e Event) MarshalJSON() ([]byte, error) {
+	raw := json.RawMessage("null")
+	if e.Err != nil {
+		raw = e.Err.JSON()
+	}
+
+	return json.Marshal(struct {
+		Time   time.Time         `json:"time"`
+		Fields map[string]string `json:"fields,omitempty"`
+		Err    json.RawMessage   `json:"error"`
+	}{
+		Time:   e.Time,
+		Fields: e.Fields,
+		Err:    raw,
+	})
+}
+
+type fieldsKey struct{}
+
+// ContextWithFields returns a copy of ctx carrying the given
+// fields along with the ones already carried by ctx. They are
+// added to the events reported with the returned context.
+func ContextWithFields(ctx context.Context, fields map[string]string) context.Context {
+	merged := maps.Clone(FieldsFromContext(ctx))
+	if merged == nil {
+		merged = make(map[string]string, len(fields))
+	}
+	maps.Copy(merged, fields)
+
+	return context.WithValue(ctx, fieldsKey{}, merged)
+}
+
+// FieldsFromContext returns the fields carried by ctx.
+// The returned map must not be modified.
+func FieldsFromContext(ctx context.Context) map[string]string {
+	if ctx == nil {
+		return nil
+	}
+
+	fields, _ := ctx.Value(fieldsKey{}).(map[string]string)
+	return fields
+}
+
+// JSONLSink writes the events to a writer as JSON lines.
+// It is safe for concurrent use.
+type JSONLSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewJSONLSink creates a new JSONLSink writing to w.
+func NewJSONLSink(w io.Writer) *JSONLSink {
+	return &JSONLSink{w: w}
+}
+
+// NewStdoutSink creates a new JSONLSink writing to the
+// standard output.
+func NewStdoutSink() *JSONLSink {
+	return NewJSONLSink(os.Stdout)
+}
+
+// Write writes every event on it's own line.
+func (s *JSONLSink) Write(_ context.Context, events []Event) error {
+	b, err := marshalJSONL(events)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err = s.w.Write(b)
+	return err
+}
+
+func marshalJSONL(events []Event) ([]byte, error) {
+	var b []byte
+	for _, e := range events {
+		line, err := json.Marshal(e)
+		if err != nil {
+			return nil, err
+		}
+		b = append(append(b, line...), '\n')
+	}
+
+	return b, nil
+}
+
+// FileSink appends the events to a file as JSON lines
+// through a buffer. It is safe for concurrent use.
+type FileSink struct {
+	mu   sync.Mutex
+	file *os.File
+	buf  *bufio.Writer
+}
+
+// NewFileSink opens or creates the file at the given path
+// and returns a FileSink appending to it.
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileSink{file: file, buf: bufio.NewWriter(file)}, nil
+}
+
+// Write writes every event on it's own line to the buffer.
+func (s *FileSink) Write(_ context.Context, events []Event) error {
+	b, err := marshalJSONL(events)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err = s.buf.Write(b)
+	return err
+}
+
+// Flush writes the buffer to the file and syncs it.
+func (s *FileSink) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.buf.Flush(); err != nil {
+		return err
+	}
+
+	return s.file.Sync()
+}
+
+// Close flushes the buffer and closes the file.
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.buf.Flush(); err != nil {
+		_ = s.file.Close()
+		return err
+	}
+
+	return s.file.Close()
+}
@@ -0,0 +1,149 @@
+package errors
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestContextWithFields(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should merge the fields of the parent context", func(t *testing.T) {
+		parent := ContextWithFields(context.Background(), map[string]string{"service": "users", "region": "eu"})
+		ctx := ContextWithFields(parent, map[string]string{"region": "us"})
+
+		got := FieldsFromContext(ctx)
+		want := map[string]string{"service": "users", "region": "us"}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("\n got:  %v\n want: %v", got, want)
+		}
+
+		if FieldsFromContext(parent)["region"] != "eu" {
+			t.Fatalf("expected the parent fields to be unchanged")
+		}
+	})
+
+	t.Run("should return nil without fields", func(t *testing.T) {
+		if got := FieldsFromContext(context.Background()); got != nil {
+			t.Fatalf("\n got:  %v\n want: %v", got, nil)
+		}
+	})
+}
+
+func TestEvent_MarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should render the error with it's JSON method", func(t *testing.T) {
+		ctx := ContextWithFields(context.Background(), map[string]string{"requestId": "abc"})
+		e := NewEvent(ctx, errors.New("boom"))
+		e.Time = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+		b, err := e.MarshalJSON()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		got := string(b)
+		want := `{"time":"2024-01-02T03:04:05Z","fields":{"requestId":"abc"},"error":{"name":"error","message":"boom","original":{}}}`
+		if got != want {
+			t.Fatalf("\n got:  %v\n want: %v", got, want)
+		}
+	})
+
+	t.Run("should render a nil error as null", func(t *testing.T) {
+		e := NewEvent(context.Background(), nil)
+		e.Time = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+		b, err := e.MarshalJSON()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if got, want := string(b), `{"time":"2024-01-02T03:04:05Z","error":null}`; got != want {
+			t.Fatalf("\n got:  %v\n want: %v", got, want)
+		}
+	})
+}
+
+func testEvents(n int) []Event {
+	events := make([]Event, 0, n)
+	for i := range n {
+		events = append(events, Event{
+			Time: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+			Err:  NewNotFoundError("not found", nil).(Error),
+			Fields: map[string]string{
+				"i": string(rune('a' + i)),
+			},
+		})
+	}
+
+	return events
+}
+
+func TestJSONLSink(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should write one event per line", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := NewJSONLSink(&buf).Write(context.Background(), testEvents(2)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		got := buf.String()
+		want := `{"time":"2024-01-02T03:04:05Z","fields":{"i":"a"},"error":{"statusCode":404,"name":"not_found_error","message":"not found","error":null}}` + "\n" +
+			`{"time":"2024-01-02T03:04:05Z","fields":{"i":"b"},"error":{"statusCode":404,"name":"not_found_error","message":"not found","error":null}}` + "\n"
+		if got != want {
+			t.Fatalf("\n got:  %v\n want: %v", got, want)
+		}
+	})
+}
+
+func TestFileSink(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should append the events to the file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "errors.jsonl")
+		if err := os.WriteFile(path, []byte("existing\n"), 0o644); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		sink, err := NewFileSink(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if err := sink.Write(context.Background(), testEvents(2)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if b, _ := os.ReadFile(path); string(b) != "existing\n" {
+			t.Fatalf("expected the events to be buffered, got %q", b)
+		}
+
+		if err := sink.Flush(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		b, _ := os.ReadFile(path)
+		if lines := strings.Split(strings.TrimSpace(string(b)), "\n"); len(lines) != 3 {
+			t.Fatalf("expected 3 lines, got %q", b)
+		}
+
+		if err := sink.Close(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("should fail to open a file in a missing directory", func(t *testing.T) {
+		if _, err := NewFileSink(filepath.Join(t.TempDir(), "missing", "errors.jsonl")); err == nil {
+			t.Fatalf("expected an error")
+		}
+	})
+}
@@ -0,0 +1,75 @@
+package errors
+
+import (
+	"runtime"
+	"strings"
+	"sync/atomic"
+)
+
+var (
+	// sourceEnabled reports whether the constructors record
+	// the location they are called from.
+	sourceEnabled atomic.Bool
+
+	// sourceTrimPrefixes are the prefixes removed from the
+	// function and file of the recorded locations.
+	sourceTrimPrefixes atomic.Pointer[[]string]
+)
+
+// EnableSource enables or disables the recording of the
+// location New, NewWithName, NewWithNameAndErr, NewHTTPError
+// and the HTTP error constructors are called from, which is
+// disabled by default.
+//
+// Only the calling frame is recorded in the Source property,
+// which is much cheaper than a full stack trace.
+func EnableSource(enabled bool) {
+	sourceEnabled.Store(enabled)
+}
+
+// TrimSourcePrefixes sets the prefixes removed from the function
+// and file of the recorded locations, like a module path
+// (e.g. "github.com/acme/app/") or a build directory. The first
+// matching prefix is removed. Calling it without prefixes
+// removes the previous ones.
+func TrimSourcePrefixes(prefixes ...string) {
+	prefixes = append([]string(nil), prefixes...)
+	sourceTrimPrefixes.Store(&prefixes)
+}
+
+// captureSource returns the location of the caller, skip being
+// the number of frames to skip above the caller of
+// captureSource, or nil when the recording is disabled.
+func captureSource(skip int) *Frame {
+	if !sourceEnabled.Load() {
+		return nil
+	}
+
+	var pc [1]uintptr
+	if runtime.Callers(skip+2, pc[:]) == 0 {
+		return nil
+	}
+
+	f, _ := runtime.CallersFrames(pc[:]).Next()
+
+	return &Frame{
+		Function: trimSource(f.Function),
+		File:     trimSource(f.File),
+		Line:     f.Line,
+	}
+}
+
+func trimSource(s string) string {
+	prefixes := sourceTrimPrefixes.Load()
+	if prefixes == nil {
+		return s
+	}
+
+	for _, prefix := range *prefixes {
+		if prefix != "" && strings.HasPrefix(s, prefix) {
+			return strings.TrimPrefix(s, prefix)
+		}
+	}
+
+	return s
+}
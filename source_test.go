@@ -0,0 +1,98 @@
+package errors
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// TestSource is not parallel since it toggles the source
+// recording, sequential tests run before the parallel ones.
+func TestSource(t *testing.T) {
+	EnableSource(true)
+	defer EnableSource(false)
+
+	line := func() int {
+		_, _, l, _ := runtime.Caller(1)
+		return l
+	}
+
+	t.Run("should record the caller of the constructors", func(t *testing.T) {
+		tests := []struct {
+			name string
+			fn   func() (error, int)
+		}{
+			{"New", func() (error, int) { return New("boom"), line() }},
+			{"NewWithName", func() (error, int) { return NewWithName("name", "boom"), line() }},
+			{"NewWithNameAndErr", func() (error, int) { return NewWithNameAndErr("name", "boom", nil), line() }},
+			{"NewHTTPError", func() (error, int) { return NewHTTPError(400, "name", "boom", nil), line() }},
+			{"NewNotFoundError", func() (error, int) { return NewNotFoundError("boom", nil), line() }},
+		}
+
+		for _, tt := range tests {
+			err, want := tt.fn()
+
+			var src *Frame
+			switch e := err.(type) {
+			case *GenericError:
+				src = e.Source
+			case *HTTPError:
+				src = e.Source
+			}
+
+			if src == nil || !strings.HasSuffix(src.File, "source_test.go") || src.Line != want {
+				t.Fatalf("%s:\n got:  %v\n want: source_test.go:%d", tt.name, src, want)
+			}
+		}
+	})
+
+	t.Run("should render the source in JSON", func(t *testing.T) {
+		TrimSourcePrefixes("github.com/iolave/")
+		defer TrimSourcePrefixes()
+
+		err := NewBadRequestError("invalid", nil).(Error)
+
+		if !strings.Contains(string(err.JSON()), `"source":{"function":"go-errors.TestSource.func`) {
+			t.Fatalf("expected a trimmed source in the JSON, got %s", err.JSON())
+		}
+	})
+
+	t.Run("should decode the source from JSON", func(t *testing.T) {
+		err := New("boom").(*GenericError)
+
+		decoded, decodeErr := FromJSON(err.JSON())
+		if decodeErr != nil {
+			t.Fatalf("unexpected error: %v", decodeErr)
+		}
+
+		got := *decoded.(*GenericError).Source
+		want := *err.Source
+		if got != want {
+			t.Fatalf("\n got:  %v\n want: %v", got, want)
+		}
+	})
+
+	t.Run("should not record when disabled", func(t *testing.T) {
+		EnableSource(false)
+		defer EnableSource(true)
+
+		if err := New("boom").(*GenericError); err.Source != nil {
+			t.Fatalf("expected no source, got %v", err.Source)
+		}
+	})
+}
+
+func TestTrimSource(t *testing.T) {
+	TrimSourcePrefixes("/build/", "github.com/acme/")
+	defer TrimSourcePrefixes()
+
+	for in, want := range map[string]string{
+		"/build/app/main.go":         "app/main.go",
+		"github.com/acme/app.Handle": "app.Handle",
+		"/other/main.go":             "/other/main.go",
+	} {
+		if got := trimSource(in); got != want {
+			t.Fatalf("\n got:  %v\n want: %v", got, want)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package errors
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// maxStackDepth is the maximum number of frames captured
+// in a stack trace.
+const maxStackDepth = 64
+
+// Frame is a frame of a stack trace.
+type Frame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// String returns the function name followed by the
+// file and line of the frame.
+func (f Frame) String() string {
+	return fmt.Sprintf("%s (%s:%d)", f.Function, f.File, f.Line)
+}
+
+// Stack is a stack trace, the first frame being the
+// innermost call.
+type Stack []Frame
+
+// String returns one frame per line.
+func (s Stack) String() string {
+	lines := make([]string, 0, len(s))
+	for _, f := range s {
+		lines = append(lines, f.String())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// callers returns the stack trace of the caller, skip being
+// the number of frames to skip above the caller of callers.
+func callers(skip int) Stack {
+	pcs := make([]uintptr, maxStackDepth)
+	n := runtime.Callers(skip+2, pcs)
+
+	return framesOf(pcs[:n])
+}
+
+// panicStack returns the stack trace at the panic site when
+// called from a deferred function recovering a panic.
+func panicStack() Stack {
+	stack := callers(1)
+	for i, f := range stack {
+		if f.Function == "runtime.gopanic" {
+			return stack[i+1:]
+		}
+	}
+
+	return stack
+}
+
+func framesOf(pcs []uintptr) Stack {
+	if len(pcs) == 0 {
+		return nil
+	}
+
+	stack := make(Stack, 0, len(pcs))
+	frames := runtime.CallersFrames(pcs)
+	for {
+		f, more := frames.Next()
+		stack = append(stack, Frame{Function: f.Function, File: f.File, Line: f.Line})
+		if !more {
+			break
+		}
+	}
+
+	return stack
+}
@@ -0,0 +1,37 @@
+package errors
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCallers(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should start at the caller", func(t *testing.T) {
+		stack := callers(0)
+		if len(stack) == 0 || !strings.HasSuffix(stack[0].Function, "TestCallers.func1") {
+			t.Fatalf("expected the stack to start at the caller, got:\n%s", stack)
+		}
+
+		if !strings.HasSuffix(stack[0].File, "stack_test.go") || stack[0].Line == 0 {
+			t.Fatalf("unexpected frame: %s", stack[0])
+		}
+	})
+}
+
+func TestStack_String(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should render one frame per line", func(t *testing.T) {
+		stack := Stack{
+			{Function: "main.main", File: "/app/main.go", Line: 10},
+			{Function: "runtime.main", File: "/go/src/runtime/proc.go", Line: 250},
+		}
+
+		want := "main.main (/app/main.go:10)\nruntime.main (/go/src/runtime/proc.go:250)"
+		if got := stack.String(); got != want {
+			t.Fatalf("\n got:  %v\n want: %v", got, want)
+		}
+	})
+}
@@ -0,0 +1,390 @@
+package errors
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// SyslogSeverity is the severity of a syslog message.
+type SyslogSeverity int
+
+// The syslog severities, from the most to the least severe.
+const (
+	SeverityEmergency SyslogSeverity = iota
+	SeverityAlert
+	SeverityCritical
+	SeverityError
+	SeverityWarning
+	SeverityNotice
+	SeverityInformational
+	SeverityDebug
+)
+
+// SyslogFacility is the facility of a syslog message.
+type SyslogFacility int
+
+// The syslog facilities of the applications.
+const (
+	FacilityUser   SyslogFacility = 1
+	FacilityDaemon SyslogFacility = 3
+	FacilityLocal0 SyslogFacility = 16
+	FacilityLocal1 SyslogFacility = 17
+	FacilityLocal2 SyslogFacility = 18
+	FacilityLocal3 SyslogFacility = 19
+	FacilityLocal4 SyslogFacility = 20
+	FacilityLocal5 SyslogFacility = 21
+	FacilityLocal6 SyslogFacility = 22
+	FacilityLocal7 SyslogFacility = 23
+)
+
+// SyslogSDID is the ID of the structured data element holding
+// the name, status code and request ID of the errors. The
+// other fields of the events are held by the "fields@32473"
+// element.
+const SyslogSDID = "goerrors@32473"
+
+// requestIDField is the event field holding the request ID.
+const requestIDField = "requestId"
+
+// ContextWithRequestID returns a copy of ctx carrying the
+// given request ID as the "requestId" field.
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return ContextWithFields(ctx, map[string]string{requestIDField: id})
+}
+
+// RequestIDFromContext returns the request ID carried by ctx.
+func RequestIDFromContext(ctx context.Context) string {
+	return FieldsFromContext(ctx)[requestIDField]
+}
+
+// DefaultSyslogSeverity returns the severity of an event:
+// critical for panics, error for 5xx status codes, warning
+// for 4xx status codes and notice otherwise.
+func DefaultSyslogSeverity(e Event) SyslogSeverity {
+	name, _, status := eventError(e)
+
+	switch {
+	case name == "panic" || hasPanic(e.Err):
+		return SeverityCritical
+	case status >= 500:
+		return SeverityError
+	case status >= 400:
+		return SeverityWarning
+	default:
+		return SeverityNotice
+	}
+}
+
+// hasPanic reports whether the error chain holds a
+// recovered panic.
+func hasPanic(err error) bool {
+	for depth := 0; err != nil && depth < maxErrorDepth; depth++ {
+		if e, ok := err.(*GenericError); ok && e.Name == "panic" {
+			return true
+		}
+		err = unwrapOnce(err)
+	}
+
+	return false
+}
+
+// SyslogFormatter formats events as RFC 5424 syslog messages.
+// Zero values use the documented defaults.
+type SyslogFormatter struct {
+	// Facility defaults to FacilityUser.
+	Facility SyslogFacility
+
+	// Hostname defaults to the host name of the machine.
+	Hostname string
+
+	// AppName defaults to the name of the executable.
+	AppName string
+
+	// ProcID defaults to the process ID.
+	ProcID string
+
+	// MsgID is the type of the messages, none by default.
+	MsgID string
+
+	// Severity returns the severity of an event, defaults
+	// to DefaultSyslogSeverity.
+	Severity func(Event) SyslogSeverity
+}
+
+// Format returns the RFC 5424 message of an event. The name,
+// status code and request ID are set to the SyslogSDID
+// structured data element and the message is the JSON
+// representation of the error.
+func (f SyslogFormatter) Format(e Event) []byte {
+	facility := f.Facility
+	if facility == 0 {
+		facility = FacilityUser
+	}
+	severity := DefaultSyslogSeverity
+	if f.Severity != nil {
+		severity = f.Severity
+	}
+
+	hostname := f.Hostname
+	if hostname == "" {
+		hostname, _ = os.Hostname()
+	}
+	appName := f.AppName
+	if appName == "" {
+		appName = filepath.Base(os.Args[0])
+	}
+	procID := f.ProcID
+	if procID == "" {
+		procID = strconv.Itoa(os.Getpid())
+	}
+
+	b := []byte{'<'}
+	b = strconv.AppendInt(b, int64(facility)*8+int64(severity(e)), 10)
+	b = append(b, ">1 "...)
+	if e.Time.IsZero() {
+		b = append(b, '-')
+	} else {
+		b = e.Time.AppendFormat(b, "2006-01-02T15:04:05.000000Z07:00")
+	}
+	b = appendHeaderField(b, hostname, 255)
+	b = appendHeaderField(b, appName, 48)
+	b = appendHeaderField(b, procID, 128)
+	b = appendHeaderField(b, f.MsgID, 32)
+	b = append(b, ' ')
+	b = appendStructuredData(b, e)
+
+	if e.Err != nil {
+		// the UTF-8 messages start with a byte order mark
+		b = append(b, " \ufeff"...)
+		b = append(b, e.Err.JSON()...)
+	}
+
+	return b
+}
+
+// appendHeaderField appends a space and the header field,
+// with it's invalid characters replaced and truncated to the
+// given length. Empty fields are appended as "-".
+func appendHeaderField(b []byte, s string, maxLen int) []byte {
+	b = append(b, ' ')
+	if s == "" {
+		return append(b, '-')
+	}
+
+	return append(b, printUSASCII(s, maxLen)...)
+}
+
+// printUSASCII replaces the characters that are not printable
+// US-ASCII with "_" and truncates s to the given length.
+func printUSASCII(s string, maxLen int) string {
+	var sb strings.Builder
+	for _, r := range s {
+		if sb.Len() == maxLen {
+			break
+		}
+		if r < 33 || r > 126 {
+			r = '_'
+		}
+		sb.WriteRune(r)
+	}
+
+	return sb.String()
+}
+
+func appendStructuredData(b []byte, e Event) []byte {
+	name, _, status := eventError(e)
+
+	b = append(b, "["+SyslogSDID...)
+	if e.Err != nil {
+		b = appendSDParam(b, "name", name)
+		b = appendSDParam(b, "status", strconv.Itoa(status))
+	}
+	if id := e.Fields[requestIDField]; id != "" {
+		b = appendSDParam(b, requestIDField, id)
+	}
+	b = append(b, ']')
+
+	var keys []string
+	for k := range e.Fields {
+		if k != requestIDField && k != "status" {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == 0 {
+		return b
+	}
+
+	slices.Sort(keys)
+	b = append(b, "[fields@32473"...)
+	for _, k := range keys {
+		b = appendSDParam(b, k, e.Fields[k])
+	}
+
+	return append(b, ']')
+}
+
+// appendSDParam appends a structured data parameter, it's
+// name is sanitized and it's value escaped.
+func appendSDParam(b []byte, name, value string) []byte {
+	name = strings.Map(func(r rune) rune {
+		if r == '=' || r == ']' || r == '"' {
+			return '_'
+		}
+		return r
+	}, printUSASCII(name, 32))
+
+	b = append(b, ' ')
+	b = append(b, name...)
+	b = append(b, `="`...)
+	for _, r := range strings.ToValidUTF8(value, string(utf8.RuneError)) {
+		if r == '"' || r == '\\' || r == ']' {
+			b = append(b, '\\')
+		}
+		b = utf8.AppendRune(b, r)
+	}
+
+	return append(b, '"')
+}
+
+// SyslogConfig configures a SyslogSink. Zero values use the
+// documented defaults.
+type SyslogConfig struct {
+	// Network is "udp", "tcp", "unix" or "unixgram" (or their
+	// variants accepted by net.Dial). Defaults to "udp".
+	//
+	// The messages sent over "tcp" and "unix" are framed with
+	// octet counting, not terminated by a newline as most local
+	// stream daemons expect. The local syslog socket (usually
+	// /dev/log) is a "unixgram" one.
+	Network string
+
+	// Addr is the address of the syslog server, or the path
+	// of it's socket for the unix networks.
+	Addr string
+
+	// Timeout is the timeout of the connection and of each
+	// write. Defaults to 5 seconds.
+	Timeout time.Duration
+
+	// Formatter formats the messages.
+	Formatter SyslogFormatter
+}
+
+// SyslogSink writes the events to a syslog server as RFC 5424
+// messages. Datagram networks get one message per datagram,
+// stream networks get them framed with their length as
+// described by RFC 6587. It is safe for concurrent use.
+type SyslogSink struct {
+	cfg SyslogConfig
+
+	mu     sync.Mutex
+	conn   net.Conn
+	closed bool
+}
+
+// NewSyslogSink connects to the syslog server of the given
+// config and returns a SyslogSink writing to it.
+func NewSyslogSink(cfg SyslogConfig) (*SyslogSink, error) {
+	if cfg.Network == "" {
+		cfg.Network = "udp"
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 5 * time.Second
+	}
+
+	s := &SyslogSink{cfg: cfg}
+	if err := s.dial(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Write writes every event as a message. A failed write is
+// retried once on a new connection. It fails once the sink
+// is closed.
+func (s *SyslogSink) Write(_ context.Context, events []Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return New("syslog sink: closed")
+	}
+
+	for _, e := range events {
+		msg := s.cfg.Formatter.Format(e)
+		if s.stream() {
+			msg = append([]byte(strconv.Itoa(len(msg))+" "), msg...)
+		}
+
+		if err := s.write(msg); err != nil {
+			if s.conn != nil {
+				_ = s.conn.Close()
+				s.conn = nil
+			}
+			if err := s.dial(); err != nil {
+				return err
+			}
+			if err := s.write(msg); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Close closes the connection.
+func (s *SyslogSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	if s.conn == nil {
+		return nil
+	}
+
+	err := s.conn.Close()
+	s.conn = nil
+
+	return err
+}
+
+func (s *SyslogSink) dial() error {
+	conn, err := net.DialTimeout(s.cfg.Network, s.cfg.Addr, s.cfg.Timeout)
+	if err != nil {
+		return err
+	}
+	s.conn = conn
+
+	return nil
+}
+
+func (s *SyslogSink) write(msg []byte) error {
+	if s.conn == nil {
+		return New("syslog sink: not connected")
+	}
+	if err := s.conn.SetWriteDeadline(time.Now().Add(s.cfg.Timeout)); err != nil {
+		return err
+	}
+
+	_, err := s.conn.Write(msg)
+	return err
+}
+
+func (s *SyslogSink) stream() bool {
+	switch s.cfg.Network {
+	case "tcp", "tcp4", "tcp6", "unix":
+		return true
+	default:
+		return false
+	}
+}
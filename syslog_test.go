@@ -0,0 +1,273 @@
+package errors
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testSyslogFormatter = SyslogFormatter{
+	Hostname: "host",
+	AppName:  "app",
+	ProcID:   "42",
+}
+
+func syslogEvent(err error, fields map[string]string) Event {
+	return Event{
+		Time:   time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Err:    wrap(err).(Error),
+		Fields: fields,
+	}
+}
+
+func TestSyslogFormatter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should format RFC 5424 messages", func(t *testing.T) {
+		e := syslogEvent(NewNotFoundError("user not found", nil), map[string]string{"requestId": "abc", "status": "404"})
+
+		got := string(testSyslogFormatter.Format(e))
+		want := `<12>1 2024-01-02T03:04:05.000000Z host app 42 - [goerrors@32473 name="not_found_error" status="404" requestId="abc"] ` +
+			"\ufeff" + `{"statusCode":404,"name":"not_found_error","message":"user not found","error":null}`
+		if got != want {
+			t.Fatalf("\n got:  %v\n want: %v", got, want)
+		}
+	})
+
+	t.Run("should map the status classes to severities", func(t *testing.T) {
+		tests := []struct {
+			err    error
+			fields map[string]string
+			want   SyslogSeverity
+		}{
+			{NewBadRequestError("invalid", nil), nil, SeverityWarning},
+			{NewBadGatewayError("bad gateway", nil), nil, SeverityError},
+			{New("boom"), nil, SeverityError},
+			{New("boom"), map[string]string{"status": "404"}, SeverityWarning},
+			{NewHTTPError(302, "found", "found", nil), nil, SeverityNotice},
+			{Safe(func() error { panic("boom") }), nil, SeverityCritical},
+			{NewInternalServerError("internal", Safe(func() error { panic("boom") })), nil, SeverityCritical},
+		}
+
+		for _, test := range tests {
+			if got := DefaultSyslogSeverity(syslogEvent(test.err, test.fields)); got != test.want {
+				t.Fatalf("%v:\n got:  %v\n want: %v", test.err, got, test.want)
+			}
+		}
+	})
+
+	t.Run("should set the priority from the facility and severity", func(t *testing.T) {
+		f := testSyslogFormatter
+		f.Facility = FacilityLocal3
+		f.Severity = func(Event) SyslogSeverity { return SeverityAlert }
+
+		if got := string(f.Format(syslogEvent(New("boom"), nil))); !strings.HasPrefix(got, "<153>1 ") {
+			t.Fatalf("unexpected message %s", got)
+		}
+	})
+
+	t.Run("should escape the structured data", func(t *testing.T) {
+		e := syslogEvent(New("boom"), map[string]string{"requestId": `a"b\c]d`, "user id": "1", "route": "GET /"})
+
+		got := string(testSyslogFormatter.Format(e))
+		want := `[goerrors@32473 name="error" status="500" requestId="a\"b\\c\]d"][fields@32473 route="GET /" user_id="1"]`
+		if !strings.Contains(got, want) {
+			t.Fatalf("\n got:  %v\n want: %v", got, want)
+		}
+	})
+
+	t.Run("should replace the invalid header characters", func(t *testing.T) {
+		f := SyslogFormatter{Hostname: "my host", AppName: strings.Repeat("a", 60), ProcID: "1", MsgID: "ÉRROR"}
+
+		got := string(f.Format(syslogEvent(New("boom"), nil)))
+		want := " my_host " + strings.Repeat("a", 48) + " 1 _RROR "
+		if !strings.Contains(got, want) {
+			t.Fatalf("\n got:  %v\n want: %v", got, want)
+		}
+	})
+}
+
+func TestRequestID(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should carry the request ID as a field", func(t *testing.T) {
+		ctx := ContextWithRequestID(context.Background(), "abc")
+
+		if got := RequestIDFromContext(ctx); got != "abc" {
+			t.Fatalf("\n got:  %v\n want: %v", got, "abc")
+		}
+
+		if got := NewEvent(ctx, New("boom")).Fields["requestId"]; got != "abc" {
+			t.Fatalf("\n got:  %v\n want: %v", got, "abc")
+		}
+	})
+
+	t.Run("should return an empty request ID", func(t *testing.T) {
+		if got := RequestIDFromContext(context.Background()); got != "" {
+			t.Fatalf("\n got:  %v\n want: %v", got, "")
+		}
+	})
+}
+
+// readFramed reads a message framed with it's length.
+func readFramed(r *bufio.Reader) (string, error) {
+	n, err := r.ReadString(' ')
+	if err != nil {
+		return "", err
+	}
+
+	size, err := strconv.Atoi(strings.TrimSuffix(n, " "))
+	if err != nil {
+		return "", err
+	}
+
+	b := make([]byte, size)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+func TestSyslogSink(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	events := []Event{
+		syslogEvent(New("first"), nil),
+		syslogEvent(New("second"), nil),
+	}
+
+	for _, network := range []string{"udp", "unixgram"} {
+		t.Run("should write one datagram per message over "+network, func(t *testing.T) {
+			addr := "127.0.0.1:0"
+			if network == "unixgram" {
+				addr = filepath.Join(t.TempDir(), "syslog.sock")
+			}
+
+			conn, err := net.ListenPacket(network, addr)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer conn.Close()
+
+			s, err := NewSyslogSink(SyslogConfig{Network: network, Addr: conn.LocalAddr().String(), Formatter: testSyslogFormatter})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer s.Close()
+
+			if err := s.Write(ctx, events); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+			buf := make([]byte, 4096)
+			for _, e := range events {
+				n, _, err := conn.ReadFrom(buf)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+
+				if got, want := string(buf[:n]), string(testSyslogFormatter.Format(e)); got != want {
+					t.Fatalf("\n got:  %v\n want: %v", got, want)
+				}
+			}
+		})
+	}
+
+	for _, network := range []string{"tcp", "unix"} {
+		t.Run("should frame the messages over "+network, func(t *testing.T) {
+			addr := "127.0.0.1:0"
+			if network == "unix" {
+				addr = filepath.Join(t.TempDir(), "syslog.sock")
+			}
+
+			ln, err := net.Listen(network, addr)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer ln.Close()
+
+			received := make(chan []string, 1)
+			go func() {
+				conn, err := ln.Accept()
+				if err != nil {
+					return
+				}
+				defer conn.Close()
+
+				var msgs []string
+				r := bufio.NewReader(conn)
+				for range events {
+					msg, err := readFramed(r)
+					if err != nil {
+						t.Errorf("unexpected error: %v", err)
+					}
+					msgs = append(msgs, msg)
+				}
+				received <- msgs
+			}()
+
+			s, err := NewSyslogSink(SyslogConfig{Network: network, Addr: ln.Addr().String(), Formatter: testSyslogFormatter})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer s.Close()
+
+			if err := s.Write(ctx, events); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			select {
+			case got := <-received:
+				for i, e := range events {
+					if want := string(testSyslogFormatter.Format(e)); got[i] != want {
+						t.Fatalf("\n got:  %v\n want: %v", got[i], want)
+					}
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("timed out waiting for the messages")
+			}
+		})
+	}
+
+	t.Run("should not reconnect once closed", func(t *testing.T) {
+		conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer conn.Close()
+
+		s, err := NewSyslogSink(SyslogConfig{Addr: conn.LocalAddr().String(), Formatter: testSyslogFormatter})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := s.Close(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if err := s.Write(ctx, events); err == nil {
+			t.Fatalf("expected an error once closed")
+		}
+
+		_ = conn.SetReadDeadline(time.Now().Add(50 * time.Millisecond))
+		if n, _, err := conn.ReadFrom(make([]byte, 4096)); err == nil {
+			t.Fatalf("unexpected message of %d bytes", n)
+		}
+	})
+
+	t.Run("should fail to connect", func(t *testing.T) {
+		addr := filepath.Join(t.TempDir(), "missing.sock")
+
+		if _, err := NewSyslogSink(SyslogConfig{Network: "unix", Addr: addr}); err == nil {
+			t.Fatalf("expected an error")
+		}
+	})
+}
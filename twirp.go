@@ -0,0 +1,210 @@
+package errors
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+)
+
+// TwirpError is the JSON representation of an error
+// returned by a Twirp service.
+//
+// See https://twitchtv.github.io/twirp/docs/spec_v7.html#error-codes
+type TwirpError struct {
+	Code string            `json:"code"`
+	Msg  string            `json:"msg"`
+	Meta map[string]string `json:"meta,omitempty"`
+}
+
+// twirpStatusCodes maps Twirp error codes to
+// the HTTP status codes specified by Twirp.
+var twirpStatusCodes = map[string]int{
+	"canceled":            http.StatusRequestTimeout,
+	"invalid_argument":    http.StatusBadRequest,
+	"malformed":           http.StatusBadRequest,
+	"deadline_exceeded":   http.StatusRequestTimeout,
+	"not_found":           http.StatusNotFound,
+	"bad_route":           http.StatusNotFound,
+	"already_exists":      http.StatusConflict,
+	"permission_denied":   http.StatusForbidden,
+	"unauthenticated":     http.StatusUnauthorized,
+	"resource_exhausted":  http.StatusTooManyRequests,
+	"failed_precondition": http.StatusPreconditionFailed,
+	"aborted":             http.StatusConflict,
+	"out_of_range":        http.StatusBadRequest,
+	"unimplemented":       http.StatusNotImplemented,
+	"internal":            http.StatusInternalServerError,
+	"unavailable":         http.StatusServiceUnavailable,
+	"dataloss":            http.StatusInternalServerError,
+	"unknown":             http.StatusInternalServerError,
+}
+
+// twirpCodes maps HTTP status codes to the Twirp
+// error code that best describes them.
+var twirpCodes = map[int]string{
+	http.StatusBadRequest:          "invalid_argument",
+	http.StatusUnauthorized:        "unauthenticated",
+	http.StatusForbidden:           "permission_denied",
+	http.StatusNotFound:            "not_found",
+	http.StatusRequestTimeout:      "deadline_exceeded",
+	http.StatusConflict:            "already_exists",
+	http.StatusPreconditionFailed:  "failed_precondition",
+	http.StatusTooManyRequests:     "resource_exhausted",
+	http.StatusInternalServerError: "internal",
+	http.StatusNotImplemented:      "unimplemented",
+	http.StatusBadGateway:          "unavailable",
+	http.StatusServiceUnavailable:  "unavailable",
+	http.StatusGatewayTimeout:      "deadline_exceeded",
+}
+
+// TwirpStatusCode returns the HTTP status code of the given
+// Twirp error code. Unknown codes are mapped to 500.
+func TwirpStatusCode(code string) int {
+	if statusCode, ok := twirpStatusCodes[code]; ok {
+		return statusCode
+	}
+
+	return http.StatusInternalServerError
+}
+
+// TwirpCode returns the Twirp error code of the given HTTP
+// status code. Status codes without a Twirp equivalent are
+// mapped to "unknown".
+func TwirpCode(statusCode int) string {
+	if code, ok := twirpCodes[statusCode]; ok {
+		return code
+	}
+
+	return "unknown"
+}
+
+// NewTwirpError converts an error to a TwirpError.
+//
+// The code is derived from the HTTPError status code, errors
+// that are not an HTTPError are treated as internal errors.
+// The error name and cause are kept in the "name" and "cause"
+// meta keys, along with the metadata of the first ErrorInfo
+// detail of the error.
+func NewTwirpError(err error) TwirpError {
+	httpErr := toHTTPError(err)
+
+	meta := map[string]string{}
+	if info, ok := FindDetail[ErrorInfo](httpErr); ok {
+		for k, v := range info.Metadata {
+			meta[k] = v
+		}
+	}
+
+	meta["name"] = httpErr.Name
+	if httpErr.Err != nil {
+		meta["cause"] = httpErr.Err.Error()
+	}
+
+	return TwirpError{
+		Code: TwirpCode(httpErr.StatusCode),
+		Msg:  httpErr.Message,
+		Meta: meta,
+	}
+}
+
+// HTTPError converts the TwirpError to an HTTPError.
+//
+// The status code is derived from the Twirp code and the
+// "name" and "cause" meta keys are restored as the name and
+// Err properties. The remaining meta keys are kept in an
+// ErrorInfo detail.
+func (e TwirpError) HTTPError() *HTTPError {
+	statusCode := TwirpStatusCode(e.Code)
+	httpErr := &HTTPError{
+		StatusCode: statusCode,
+		Name:       statusName(statusCode),
+		Message:    e.Msg,
+	}
+
+	metadata := map[string]string{}
+	for k, v := range e.Meta {
+		switch k {
+		case "name":
+			httpErr.Name = v
+		case "cause":
+			httpErr.Err = New(v)
+		default:
+			metadata[k] = v
+		}
+	}
+
+	if len(metadata) > 0 {
+		httpErr.WithDetails(ErrorInfo{Reason: e.Code, Metadata: metadata})
+	}
+
+	return httpErr
+}
+
+// WriteTwirpError writes the given error to w as a Twirp
+// JSON error with the status code specified for it's code.
+func WriteTwirpError(w http.ResponseWriter, err error) {
+	twirpErr := NewTwirpError(err)
+
+	b, marshalErr := json.Marshal(twirpErr)
+	if marshalErr != nil {
+		twirpErr.Meta = nil
+		b, _ = json.Marshal(twirpErr)
+	}
+
+	statusCode := TwirpStatusCode(twirpErr.Code)
+	onWritten(w, nil, err, statusCode)
+	writeHeader(w, err)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_, _ = w.Write(b)
+}
+
+// ParseTwirpError decodes a Twirp JSON error and converts
+// it to an HTTPError.
+func ParseTwirpError(b []byte) (*HTTPError, error) {
+	var twirpErr TwirpError
+	if err := json.Unmarshal(b, &twirpErr); err != nil {
+		return nil, NewWithNameAndErr("error", "invalid twirp error", err)
+	}
+
+	if twirpErr.Code == "" {
+		return nil, New("invalid twirp error: missing code")
+	}
+
+	return twirpErr.HTTPError(), nil
+}
+
+// toHTTPError returns err as an HTTPError. Errors that
+// are not an HTTPError are converted to an internal server
+// error keeping the GenericError name and message, other
+// errors are kept as the message only.
+func toHTTPError(err error) *HTTPError {
+	var httpErr *HTTPError
+	if stderrors.As(err, &httpErr) {
+		return httpErr
+	}
+
+	switch e := err.(type) {
+	case GenericError:
+		return toHTTPError(&e)
+	case *GenericError:
+		return &HTTPError{
+			StatusCode: http.StatusInternalServerError,
+			Name:       e.Name,
+			Message:    e.Message,
+			Err:        e.Original,
+			Details:    e.Details,
+		}
+	case nil:
+		return &HTTPError{
+			StatusCode: http.StatusInternalServerError,
+			Name:       statusName(http.StatusInternalServerError),
+		}
+	}
+
+	return &HTTPError{
+		StatusCode: http.StatusInternalServerError,
+		Name:       statusName(http.StatusInternalServerError),
+		Message:    err.Error(),
+	}
+}
@@ -0,0 +1,136 @@
+package errors
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTwirpStatusCode(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]int{
+		"canceled":            http.StatusRequestTimeout,
+		"invalid_argument":    http.StatusBadRequest,
+		"not_found":           http.StatusNotFound,
+		"bad_route":           http.StatusNotFound,
+		"failed_precondition": http.StatusPreconditionFailed,
+		"resource_exhausted":  http.StatusTooManyRequests,
+		"unimplemented":       http.StatusNotImplemented,
+		"dataloss":            http.StatusInternalServerError,
+		"something_else":      http.StatusInternalServerError,
+	}
+
+	for code, want := range tests {
+		if got := TwirpStatusCode(code); got != want {
+			t.Errorf("TwirpStatusCode(%q) = %d, want %d", code, got, want)
+		}
+	}
+}
+
+func TestTwirpCode(t *testing.T) {
+	t.Parallel()
+
+	tests := map[int]string{
+		http.StatusBadRequest:          "invalid_argument",
+		http.StatusUnauthorized:        "unauthenticated",
+		http.StatusNotFound:            "not_found",
+		http.StatusServiceUnavailable:  "unavailable",
+		http.StatusInternalServerError: "internal",
+		http.StatusTeapot:              "unknown",
+	}
+
+	for statusCode, want := range tests {
+		if got := TwirpCode(statusCode); got != want {
+			t.Errorf("TwirpCode(%d) = %q, want %q", statusCode, got, want)
+		}
+	}
+}
+
+func TestNewTwirpError(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should convert an http error", func(t *testing.T) {
+		err := NewNotFoundError("user not found", New("no rows")).(*HTTPError).
+			WithDetails(ErrorInfo{Reason: "USER_NOT_FOUND", Metadata: map[string]string{"id": "42"}})
+
+		want := `{"code":"not_found","msg":"user not found","meta":{"cause":"error: no rows","id":"42","name":"not_found_error"}}`
+		b, _ := json.Marshal(NewTwirpError(err))
+
+		if got := string(b); got != want {
+			t.Fatalf("\n got:  %v\n want: %v", got, want)
+		}
+	})
+
+	t.Run("should convert a generic error to an internal error", func(t *testing.T) {
+		got := NewTwirpError(NewWithName("db_error", "connection refused"))
+
+		if got.Code != "internal" || got.Msg != "connection refused" || got.Meta["name"] != "db_error" {
+			t.Fatalf("unexpected twirp error: %#v", got)
+		}
+	})
+}
+
+func TestParseTwirpError(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should round trip an http error", func(t *testing.T) {
+		want := NewConflictError("user exists", nil).(*HTTPError)
+		b, _ := json.Marshal(NewTwirpError(want))
+
+		got, err := ParseTwirpError(b)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if string(got.JSON()) != string(want.JSON()) {
+			t.Fatalf("\n got:  %v\n want: %v", string(got.JSON()), string(want.JSON()))
+		}
+	})
+
+	t.Run("should use the status name when meta has no name", func(t *testing.T) {
+		got, err := ParseTwirpError([]byte(`{"code":"permission_denied","msg":"nope","meta":{"user":"1"}}`))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if got.StatusCode != http.StatusForbidden || got.Name != "forbidden_error" {
+			t.Fatalf("unexpected http error: %v", got)
+		}
+
+		if info, ok := FindDetail[ErrorInfo](got); !ok || info.Metadata["user"] != "1" {
+			t.Fatalf("expected meta to be kept in an ErrorInfo detail, got %#v", got.Details)
+		}
+	})
+
+	t.Run("should fail on invalid errors", func(t *testing.T) {
+		for _, in := range []string{`nope`, `{"msg":"missing code"}`} {
+			if _, err := ParseTwirpError([]byte(in)); err == nil {
+				t.Fatalf("expected an error for %s", in)
+			}
+		}
+	})
+}
+
+func TestWriteTwirpError(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should write the twirp status code and body", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		WriteTwirpError(rec, NewTooManyRequestsError("slow down", nil))
+
+		if rec.Code != http.StatusTooManyRequests {
+			t.Fatalf("\n got:  %v\n want: %v", rec.Code, http.StatusTooManyRequests)
+		}
+
+		if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+			t.Fatalf("\n got:  %v\n want: %v", ct, "application/json")
+		}
+
+		want := `{"code":"resource_exhausted","msg":"slow down","meta":{"name":"too_many_requests_error"}}`
+		if got := rec.Body.String(); got != want {
+			t.Fatalf("\n got:  %v\n want: %v", got, want)
+		}
+	})
+}
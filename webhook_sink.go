@@ -0,0 +1,342 @@
+package errors
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// WebhookSignatureHeader is the header of the HMAC-SHA256
+// signature of the webhook payloads, formatted as
+// "sha256=<hex digest>".
+const WebhookSignatureHeader = "X-Signature-256"
+
+// WebhookRule defines which errors trigger a webhook alert.
+type WebhookRule struct {
+	// Name identifies the rule in the alerts.
+	Name string
+
+	// Names are the error names matched by the rule,
+	// any name matches when it is empty.
+	Names []string
+
+	// StatusClass is the class of the status codes matched
+	// by the rule (i.e. 4 for 4xx and 5 for 5xx status codes),
+	// any status code matches when it is 0. The status code
+	// is the "status" field of the event when it is set, the
+	// one of the error otherwise.
+	StatusClass int
+
+	// Threshold is the number of matching errors within the
+	// window that triggers an alert. Defaults to 1.
+	Threshold int
+
+	// Window is the duration the matching errors are counted
+	// over. Defaults to 1 minute.
+	Window time.Duration
+}
+
+// WebhookConfig configures a WebhookSink. Zero values use
+// the documented defaults.
+type WebhookConfig struct {
+	// URL is the URL the alerts are posted to.
+	URL string
+
+	// Secret is the key of the HMAC-SHA256 signature sent in
+	// the WebhookSignatureHeader header. Payloads are not
+	// signed when it is empty.
+	Secret string
+
+	// Timeout is the timeout of each request. Defaults to
+	// 10 seconds.
+	Timeout time.Duration
+
+	// Retry is the retry policy of the requests, failed
+	// requests are retried when the status code is retryable
+	// or the request could not be sent.
+	Retry RetryPolicy
+
+	// MaxRetryWait is the maximum delay before retrying a
+	// request, the longer delays requested by the webhook with
+	// a Retry-After header are capped to it. Defaults to the
+	// MaxDelay of the retry policy.
+	MaxRetryWait time.Duration
+
+	// Rules are the rules triggering the alerts. By default,
+	// every error triggers an alert.
+	Rules []WebhookRule
+
+	// Client is the HTTP client. Defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+// WebhookAlert is an alert triggered by a WebhookRule.
+type WebhookAlert struct {
+	Rule   string         `json:"rule"`
+	Count  int            `json:"count"`
+	Window string         `json:"window"`
+	Errors []ErrorSummary `json:"errors"`
+}
+
+// ErrorSummary summarises the errors of a batch with the
+// same name and status code.
+type ErrorSummary struct {
+	Name       string    `json:"name"`
+	StatusCode int       `json:"statusCode"`
+	Message    string    `json:"message"`
+	Count      int       `json:"count"`
+	FirstSeen  time.Time `json:"firstSeen"`
+	LastSeen   time.Time `json:"lastSeen"`
+}
+
+// WebhookPayload is the JSON body posted by a WebhookSink.
+type WebhookPayload struct {
+	Alerts []WebhookAlert `json:"alerts"`
+}
+
+// WebhookSink posts alerts summarising the reported errors to
+// a webhook when they match it's rules. It is safe for
+// concurrent use.
+type WebhookSink struct {
+	cfg WebhookConfig
+
+	// closing is canceled by Close to stop the pending writes
+	closing context.Context
+	close   context.CancelFunc
+
+	mu   sync.Mutex
+	hits [][]time.Time
+}
+
+// NewWebhookSink creates a new WebhookSink.
+func NewWebhookSink(cfg WebhookConfig) *WebhookSink {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.Client == nil {
+		cfg.Client = http.DefaultClient
+	}
+	if cfg.MaxRetryWait <= 0 {
+		cfg.MaxRetryWait = cfg.Retry.withDefaults().MaxDelay
+	}
+	if len(cfg.Rules) == 0 {
+		cfg.Rules = []WebhookRule{{Name: "errors"}}
+	}
+
+	cfg.Rules = slices.Clone(cfg.Rules)
+	for i := range cfg.Rules {
+		if cfg.Rules[i].Threshold <= 0 {
+			cfg.Rules[i].Threshold = 1
+		}
+		if cfg.Rules[i].Window <= 0 {
+			cfg.Rules[i].Window = time.Minute
+		}
+	}
+
+	closing, cancel := context.WithCancel(context.Background())
+	return &WebhookSink{
+		cfg:     cfg,
+		closing: closing,
+		close:   cancel,
+		hits:    make([][]time.Time, len(cfg.Rules)),
+	}
+}
+
+// Close cancels the pending requests and retries of the
+// writes, the following writes fail.
+func (s *WebhookSink) Close() error {
+	s.close()
+	return nil
+}
+
+// Write evaluates the rules against the events and posts the
+// triggered alerts in a single request. The rates are computed
+// from the time of the events, the counter of a rule is reset
+// once it triggered an alert.
+//
+// The retries stop when ctx is done or the sink is closed.
+func (s *WebhookSink) Write(ctx context.Context, events []Event) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stop := context.AfterFunc(s.closing, cancel)
+	defer stop()
+
+	alerts := s.alerts(events)
+	if len(alerts) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(WebhookPayload{Alerts: alerts})
+	if err != nil {
+		return err
+	}
+
+	return Retry(ctx, s.cfg.Retry, func(ctx context.Context) error {
+		return s.post(ctx, body)
+	})
+}
+
+func (s *WebhookSink) alerts(events []Event) []WebhookAlert {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var alerts []WebhookAlert
+	for i, rule := range s.cfg.Rules {
+		var matched []Event
+		for _, e := range events {
+			if rule.match(e) {
+				matched = append(matched, e)
+				s.hits[i] = append(s.hits[i], e.Time)
+			}
+		}
+		if len(matched) == 0 {
+			continue
+		}
+
+		latest := slices.MaxFunc(s.hits[i], time.Time.Compare)
+		s.hits[i] = slices.DeleteFunc(s.hits[i], func(t time.Time) bool {
+			return latest.Sub(t) >= rule.Window
+		})
+
+		if len(s.hits[i]) < rule.Threshold {
+			continue
+		}
+
+		alerts = append(alerts, WebhookAlert{
+			Rule:   rule.Name,
+			Count:  len(s.hits[i]),
+			Window: rule.Window.String(),
+			Errors: summarize(matched),
+		})
+		s.hits[i] = nil
+	}
+
+	return alerts
+}
+
+func (r WebhookRule) match(e Event) bool {
+	if e.Err == nil {
+		return false
+	}
+
+	name, _, status := eventError(e)
+	if len(r.Names) > 0 && !slices.Contains(r.Names, name) {
+		return false
+	}
+
+	return r.StatusClass == 0 || status/100 == r.StatusClass
+}
+
+// eventError returns the name, message and status code of
+// the error of an event.
+func eventError(e Event) (name, msg string, status int) {
+	httpErr := toHTTPError(e.Err)
+	status = httpErr.StatusCode
+	if s, err := strconv.Atoi(e.Fields["status"]); err == nil {
+		status = s
+	}
+
+	return httpErr.Name, httpErr.Message, status
+}
+
+// summarize groups the events by error name and status code,
+// in order of first appearance.
+func summarize(events []Event) []ErrorSummary {
+	var summaries []ErrorSummary
+	index := map[string]int{}
+
+	for _, e := range events {
+		name, msg, status := eventError(e)
+		key := fmt.Sprintf("%s\xff%d", name, status)
+
+		i, ok := index[key]
+		if !ok {
+			index[key] = len(summaries)
+			summaries = append(summaries, ErrorSummary{
+				Name:       name,
+				StatusCode: status,
+				Message:    msg,
+				FirstSeen:  e.Time,
+				LastSeen:   e.Time,
+			})
+			i = len(summaries) - 1
+		}
+
+		sum := &summaries[i]
+		sum.Count++
+		if e.Time.Before(sum.FirstSeen) {
+			sum.FirstSeen = e.Time
+		}
+		if e.Time.After(sum.LastSeen) {
+			sum.LastSeen = e.Time
+		}
+	}
+
+	return summaries
+}
+
+func (s *WebhookSink) post(ctx context.Context, body []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", MediaTypeJSON)
+	if s.cfg.Secret != "" {
+		req.Header.Set(WebhookSignatureHeader, SignWebhook(s.cfg.Secret, body))
+	}
+
+	resp, err := s.cfg.Client.Do(req)
+	if err != nil {
+		return NewWithNameAndErr("webhook_error", "webhook request failed", err).(*GenericError).WithRetryable(true)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBody))
+		return nil
+	}
+
+	httpErr, err := FromResponse(resp)
+	if err != nil {
+		return err
+	}
+
+	if d, ok := retryAfter(httpErr); ok && d > s.cfg.MaxRetryWait && Retryable(httpErr) {
+		capRetryAfter(httpErr, s.cfg.MaxRetryWait)
+	}
+
+	return httpErr
+}
+
+// capRetryAfter replaces the delay requested by the error
+// with the given one.
+func capRetryAfter(e *HTTPError, d time.Duration) {
+	e.Header.Del("Retry-After")
+	e.Details = slices.DeleteFunc(e.Details, func(detail Detail) bool {
+		_, ok := detail.(RetryInfo)
+		return ok
+	})
+	e.Details = append(e.Details, RetryInfo{RetryDelay: d})
+}
+
+// SignWebhook returns the value of the WebhookSignatureHeader
+// header of the given payload, to verify the webhook requests.
+func SignWebhook(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
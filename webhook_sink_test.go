@@ -0,0 +1,316 @@
+package errors
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// webhookServer is a test webhook recording the payloads
+// it receives.
+type webhookServer struct {
+	*httptest.Server
+
+	mu         sync.Mutex
+	payloads   []WebhookPayload
+	signatures []string
+}
+
+func newWebhookServer(t *testing.T, handler func(w http.ResponseWriter, attempt int) bool) *webhookServer {
+	t.Helper()
+
+	s := &webhookServer{}
+	attempts := 0
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		attempts++
+		attempt := attempts
+		s.mu.Unlock()
+
+		if handler != nil && !handler(w, attempt) {
+			return
+		}
+
+		b, _ := io.ReadAll(r.Body)
+		var p WebhookPayload
+		if err := json.Unmarshal(b, &p); err != nil {
+			t.Errorf("unexpected payload %s: %v", b, err)
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		s.payloads = append(s.payloads, p)
+		s.signatures = append(s.signatures, r.Header.Get(WebhookSignatureHeader))
+
+		if got := r.Header.Get(WebhookSignatureHeader); got != "" && got != SignWebhook("secret", b) {
+			t.Errorf("invalid signature %s", got)
+		}
+	}))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func (s *webhookServer) received() ([]WebhookPayload, []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.payloads, s.signatures
+}
+
+func webhookEvent(err error, status string, at time.Time) Event {
+	e := Event{Time: at, Err: wrap(err).(Error)}
+	if status != "" {
+		e.Fields = map[string]string{"status": status}
+	}
+
+	return e
+}
+
+func TestWebhookSink(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	fastRetry := RetryPolicy{MaxAttempts: 3, InitialDelay: time.Millisecond}
+
+	t.Run("should post signed summaries of the errors", func(t *testing.T) {
+		srv := newWebhookServer(t, nil)
+		sink := NewWebhookSink(WebhookConfig{URL: srv.URL, Secret: "secret"})
+
+		events := []Event{
+			webhookEvent(NewNotFoundError("user not found", nil), "", now),
+			webhookEvent(NewNotFoundError("order not found", nil), "", now.Add(time.Second)),
+			webhookEvent(New("boom"), "", now),
+		}
+		if err := sink.Write(ctx, events); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		payloads, signatures := srv.received()
+		if len(payloads) != 1 || len(payloads[0].Alerts) != 1 {
+			t.Fatalf("unexpected payloads: %+v", payloads)
+		}
+		if signatures[0] == "" {
+			t.Fatalf("expected a signature")
+		}
+
+		alert := payloads[0].Alerts[0]
+		if alert.Rule != "errors" || alert.Count != 3 || alert.Window != "1m0s" {
+			t.Fatalf("unexpected alert: %+v", alert)
+		}
+
+		want := []ErrorSummary{
+			{Name: "not_found_error", StatusCode: 404, Message: "user not found", Count: 2, FirstSeen: now, LastSeen: now.Add(time.Second)},
+			{Name: "error", StatusCode: 500, Message: "boom", Count: 1, FirstSeen: now, LastSeen: now},
+		}
+		if len(alert.Errors) != len(want) {
+			t.Fatalf("\n got:  %+v\n want: %+v", alert.Errors, want)
+		}
+		for i := range want {
+			got := alert.Errors[i]
+			if got.Name != want[i].Name || got.StatusCode != want[i].StatusCode || got.Message != want[i].Message ||
+				got.Count != want[i].Count || !got.FirstSeen.Equal(want[i].FirstSeen) || !got.LastSeen.Equal(want[i].LastSeen) {
+				t.Fatalf("\n got:  %+v\n want: %+v", got, want[i])
+			}
+		}
+	})
+
+	t.Run("should not sign without a secret", func(t *testing.T) {
+		srv := newWebhookServer(t, nil)
+		sink := NewWebhookSink(WebhookConfig{URL: srv.URL})
+
+		if err := sink.Write(ctx, []Event{webhookEvent(New("boom"), "", now)}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if _, signatures := srv.received(); len(signatures) != 1 || signatures[0] != "" {
+			t.Fatalf("unexpected signatures: %v", signatures)
+		}
+	})
+
+	t.Run("should match the names and status classes", func(t *testing.T) {
+		srv := newWebhookServer(t, nil)
+		sink := NewWebhookSink(WebhookConfig{URL: srv.URL, Rules: []WebhookRule{
+			{Name: "unauthorized", Names: []string{"unauthorized_error"}},
+			{Name: "5xx", StatusClass: 5},
+		}})
+
+		events := []Event{
+			webhookEvent(NewNotFoundError("not found", nil), "", now),
+			webhookEvent(NewUnauthorizedError("unauthorized", nil), "", now),
+			webhookEvent(NewNotFoundError("not found", nil), "503", now),
+		}
+		if err := sink.Write(ctx, events); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		payloads, _ := srv.received()
+		if len(payloads) != 1 || len(payloads[0].Alerts) != 2 {
+			t.Fatalf("unexpected payloads: %+v", payloads)
+		}
+
+		alerts := payloads[0].Alerts
+		if alerts[0].Rule != "unauthorized" || alerts[0].Errors[0].Name != "unauthorized_error" {
+			t.Fatalf("unexpected alert: %+v", alerts[0])
+		}
+		if alerts[1].Rule != "5xx" || alerts[1].Count != 1 || alerts[1].Errors[0].StatusCode != 503 {
+			t.Fatalf("unexpected alert: %+v", alerts[1])
+		}
+	})
+
+	t.Run("should alert once the threshold is reached within the window", func(t *testing.T) {
+		srv := newWebhookServer(t, nil)
+		sink := NewWebhookSink(WebhookConfig{URL: srv.URL, Rules: []WebhookRule{
+			{Name: "5xx", StatusClass: 5, Threshold: 3, Window: time.Minute},
+		}})
+
+		batches := [][]Event{
+			{webhookEvent(New("boom"), "", now), webhookEvent(New("boom"), "", now)},
+			// the first errors are out of the window
+			{webhookEvent(New("boom"), "", now.Add(time.Minute))},
+			{webhookEvent(New("boom"), "", now.Add(70*time.Second))},
+			{webhookEvent(New("boom"), "", now.Add(80*time.Second))},
+			// the counter was reset by the alert
+			{webhookEvent(New("boom"), "", now.Add(90*time.Second))},
+		}
+		for _, batch := range batches {
+			if err := sink.Write(ctx, batch); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+
+		payloads, _ := srv.received()
+		if len(payloads) != 1 || payloads[0].Alerts[0].Count != 3 {
+			t.Fatalf("unexpected payloads: %+v", payloads)
+		}
+	})
+
+	t.Run("should not post without alerts", func(t *testing.T) {
+		srv := newWebhookServer(t, nil)
+		sink := NewWebhookSink(WebhookConfig{URL: srv.URL, Rules: []WebhookRule{{Name: "5xx", StatusClass: 5}}})
+
+		if err := sink.Write(ctx, testEvents(2)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if payloads, _ := srv.received(); len(payloads) != 0 {
+			t.Fatalf("unexpected payloads: %+v", payloads)
+		}
+	})
+
+	t.Run("should retry the retryable failures", func(t *testing.T) {
+		srv := newWebhookServer(t, func(w http.ResponseWriter, attempt int) bool {
+			if attempt < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return false
+			}
+			return true
+		})
+		sink := NewWebhookSink(WebhookConfig{URL: srv.URL, Retry: fastRetry})
+
+		if err := sink.Write(ctx, testEvents(1)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if payloads, _ := srv.received(); len(payloads) != 1 {
+			t.Fatalf("unexpected payloads: %+v", payloads)
+		}
+	})
+
+	t.Run("should not retry the non-retryable failures", func(t *testing.T) {
+		attempts := 0
+		srv := newWebhookServer(t, func(w http.ResponseWriter, attempt int) bool {
+			attempts = attempt
+			w.WriteHeader(http.StatusBadRequest)
+			return false
+		})
+		sink := NewWebhookSink(WebhookConfig{URL: srv.URL, Retry: fastRetry})
+
+		err := sink.Write(ctx, testEvents(1))
+		if err == nil {
+			t.Fatalf("expected an error")
+		}
+
+		if got, want := err.Error(), "retry_error: attempt 1 failed with a non-retryable error"; !strings.HasPrefix(got, want) {
+			t.Fatalf("\n got:  %v\n want: %v", got, want)
+		}
+		if attempts != 1 {
+			t.Fatalf("expected 1 attempt, got %d", attempts)
+		}
+	})
+
+	t.Run("should time out the requests", func(t *testing.T) {
+		release := make(chan struct{})
+		srv := newWebhookServer(t, func(w http.ResponseWriter, attempt int) bool {
+			<-release
+			return false
+		})
+		defer close(release)
+
+		sink := NewWebhookSink(WebhookConfig{URL: srv.URL, Timeout: 10 * time.Millisecond, Retry: RetryPolicy{MaxAttempts: 2, InitialDelay: time.Millisecond}})
+
+		err := sink.Write(ctx, testEvents(1))
+		if err == nil {
+			t.Fatalf("expected an error")
+		}
+
+		if got, want := err.Error(), "retry_error: all 2 attempts failed"; !strings.HasPrefix(got, want) {
+			t.Fatalf("\n got:  %v\n want: %v", got, want)
+		}
+	})
+
+	t.Run("should cap the Retry-After delay", func(t *testing.T) {
+		srv := newWebhookServer(t, func(w http.ResponseWriter, attempt int) bool {
+			if attempt < 2 {
+				w.Header().Set("Retry-After", "3600")
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return false
+			}
+			return true
+		})
+		sink := NewWebhookSink(WebhookConfig{URL: srv.URL, Retry: fastRetry, MaxRetryWait: 10 * time.Millisecond})
+
+		start := time.Now()
+		if err := sink.Write(ctx, testEvents(1)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Fatalf("expected the delay to be capped, waited %s", elapsed)
+		}
+		if payloads, _ := srv.received(); len(payloads) != 1 {
+			t.Fatalf("unexpected payloads: %+v", payloads)
+		}
+	})
+
+	t.Run("should stop retrying when closed", func(t *testing.T) {
+		attempted := make(chan struct{}, 1)
+		srv := newWebhookServer(t, func(w http.ResponseWriter, attempt int) bool {
+			select {
+			case attempted <- struct{}{}:
+			default:
+			}
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return false
+		})
+		sink := NewWebhookSink(WebhookConfig{URL: srv.URL, Retry: RetryPolicy{MaxAttempts: 3, InitialDelay: time.Hour}})
+
+		go func() {
+			<-attempted
+			sink.Close()
+		}()
+
+		err := sink.Write(ctx, testEvents(1))
+		if want := "retry_error: context done after 1 attempts"; err == nil || !strings.HasPrefix(err.Error(), want) {
+			t.Fatalf("\n got:  %v\n want: %v", err, want)
+		}
+	})
+}
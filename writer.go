@@ -0,0 +1,213 @@
+package errors
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Media types supported by WriteError.
+const (
+	MediaTypeJSON        = "application/json"
+	MediaTypeProblemJSON = "application/problem+json"
+	MediaTypeXML         = "application/xml"
+	MediaTypeText        = "text/plain"
+	MediaTypeTextXML     = "text/xml"
+)
+
+// offers are the media types that WriteError can render,
+// in order of preference when the client accepts several
+// of them with the same quality.
+var offers = []string{
+	MediaTypeJSON,
+	MediaTypeProblemJSON,
+	MediaTypeXML,
+	MediaTypeText,
+	MediaTypeTextXML,
+	MediaTypeHTML,
+}
+
+// WriteError writes the given error to w using the
+// representation that best matches the Accept header of r.
+// HTML pages are rendered by the DefaultHTMLRenderer.
+//
+// The status code and headers are the HTTPError ones, errors
+// that are not an HTTPError are written with a 500 status code.
+// When the request has no Accept header or none of the
+// supported media types is acceptable, the error is written
+// as JSON.
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	if err == nil {
+		err = toHTTPError(nil)
+	}
+
+	accept := ""
+	if r != nil {
+		accept = r.Header.Get("Accept")
+	}
+
+	mediaType := Negotiate(accept, offers...)
+	if mediaType == "" {
+		mediaType = MediaTypeJSON
+	}
+
+	var body []byte
+	switch mediaType {
+	case MediaTypeProblemJSON:
+		body = ProblemJSON(err)
+	case MediaTypeXML, MediaTypeTextXML:
+		body = renderXML(err)
+		mediaType += "; charset=utf-8"
+	case MediaTypeText:
+		body = append(renderText(err), '\n')
+		mediaType += "; charset=utf-8"
+	case MediaTypeHTML:
+		body = DefaultHTMLRenderer.Render(err)
+		mediaType += "; charset=utf-8"
+	default:
+		body = wrap(err).(Error).JSON()
+	}
+
+	statusCode := toHTTPError(err).StatusCode
+	if !ValidStatusCode(statusCode) {
+		statusCode = http.StatusInternalServerError
+	}
+
+	onWritten(w, r, err, statusCode)
+	writeHeader(w, err)
+	w.Header().Set("Content-Type", mediaType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(statusCode)
+	_, _ = w.Write(body)
+}
+
+// onWritten is called by the functions writing errors to
+// HTTP responses with the status code of the response. The
+// request is nil when it is not known.
+func onWritten(w http.ResponseWriter, r *http.Request, err error, statusCode int) {
+	countWritten(err, statusCode)
+	recordError(w, err)
+	recordRecent(r, err, statusCode)
+	reportWritten(r, err, statusCode)
+}
+
+func renderXML(err error) []byte {
+	if e, ok := err.(interface{ XML() []byte }); ok {
+		return e.XML()
+	}
+
+	return marshalXMLError(err)
+}
+
+func renderText(err error) []byte {
+	if e, ok := err.(interface{ Text() []byte }); ok {
+		return e.Text()
+	}
+
+	return []byte(err.Error())
+}
+
+// Negotiate returns the offer that best matches the given
+// Accept header value, or an empty string if none of the
+// offers is acceptable.
+//
+// Offers are compared against the most specific matching
+// media range of the header and its quality (q) value. Offers
+// with the same quality are resolved in the given order. An
+// empty header accepts the first offer.
+func Negotiate(accept string, offers ...string) string {
+	if len(offers) == 0 {
+		return ""
+	}
+
+	if strings.TrimSpace(accept) == "" {
+		return offers[0]
+	}
+
+	ranges := parseAccept(accept)
+	best, bestQ := "", 0.0
+	for _, offer := range offers {
+		q := acceptQuality(ranges, offer)
+		if q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+
+	return best
+}
+
+// mediaRange is a media range of an Accept header.
+type mediaRange struct {
+	typ     string
+	subtype string
+	q       float64
+}
+
+// specificity returns how specific the media range is,
+// "*/*" being the least specific.
+func (m mediaRange) specificity() int {
+	switch {
+	case m.typ == "*":
+		return 0
+	case m.subtype == "*":
+		return 1
+	}
+
+	return 2
+}
+
+func (m mediaRange) matches(typ, subtype string) bool {
+	if m.typ == "*" {
+		return true
+	}
+
+	return m.typ == typ && (m.subtype == "*" || m.subtype == subtype)
+}
+
+func parseAccept(accept string) []mediaRange {
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		typ, subtype, ok := strings.Cut(strings.ToLower(strings.TrimSpace(params[0])), "/")
+		if !ok || typ == "" || subtype == "" {
+			continue
+		}
+
+		m := mediaRange{typ: typ, subtype: subtype, q: 1}
+		for _, param := range params[1:] {
+			key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.ToLower(strings.TrimSpace(key)) != "q" {
+				continue
+			}
+
+			q, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil || q < 0 || q > 1 {
+				q = 0
+			}
+			m.q = q
+		}
+
+		ranges = append(ranges, m)
+	}
+
+	return ranges
+}
+
+// acceptQuality returns the quality of the most specific
+// media range matching the offer.
+func acceptQuality(ranges []mediaRange, offer string) float64 {
+	typ, subtype, _ := strings.Cut(strings.ToLower(offer), "/")
+
+	q, specificity := 0.0, -1
+	for _, m := range ranges {
+		if !m.matches(typ, subtype) {
+			continue
+		}
+
+		if s := m.specificity(); s > specificity {
+			q, specificity = m.q, s
+		}
+	}
+
+	return q
+}
@@ -0,0 +1,90 @@
+package errors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNegotiate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		accept string
+		want   string
+	}{
+		{"", MediaTypeJSON},
+		{"*/*", MediaTypeJSON},
+		{"application/xml", MediaTypeXML},
+		{"text/*", MediaTypeText},
+		{"text/xml", MediaTypeTextXML},
+		{"text/plain, application/json;q=0.5", MediaTypeText},
+		{"application/json;q=0.5, application/problem+json", MediaTypeProblemJSON},
+		{"application/*;q=0.2, application/xml;q=0.9, */*;q=0.1", MediaTypeXML},
+		{"text/csv, */*;q=0.8", MediaTypeJSON},
+		{"text/html, */*;q=0.8", MediaTypeHTML},
+		{"*/*, application/json;q=0", MediaTypeProblemJSON},
+		{"image/png", ""},
+		{"application/json;q=nope", ""},
+		{"TEXT/PLAIN", MediaTypeText},
+	}
+
+	for _, tt := range tests {
+		if got := Negotiate(tt.accept, offers...); got != tt.want {
+			t.Errorf("Negotiate(%q) = %q, want %q", tt.accept, got, tt.want)
+		}
+	}
+}
+
+func TestWriteError(t *testing.T) {
+	t.Parallel()
+
+	err := NewNotFoundError("user not found", nil)
+	tests := []struct {
+		accept      string
+		contentType string
+		body        string
+	}{
+		{"", MediaTypeJSON, string(err.(Error).JSON())},
+		{"application/problem+json", MediaTypeProblemJSON, string(ProblemJSON(err))},
+		{"application/xml", "application/xml; charset=utf-8", string(err.(*HTTPError).XML())},
+		{"text/plain", "text/plain; charset=utf-8", "404 not_found_error: user not found\n"},
+		{"image/png", MediaTypeJSON, string(err.(Error).JSON())},
+	}
+
+	for _, tt := range tests {
+		t.Run("should write "+tt.contentType, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set("Accept", tt.accept)
+			rec := httptest.NewRecorder()
+
+			WriteError(rec, r, err)
+
+			if rec.Code != http.StatusNotFound {
+				t.Fatalf("\n got:  %v\n want: %v", rec.Code, http.StatusNotFound)
+			}
+
+			if got := rec.Header().Get("Content-Type"); got != tt.contentType {
+				t.Fatalf("\n got:  %v\n want: %v", got, tt.contentType)
+			}
+
+			if got := rec.Body.String(); got != tt.body {
+				t.Fatalf("\n got:  %v\n want: %v", got, tt.body)
+			}
+		})
+	}
+
+	t.Run("should write errors that are not an HTTPError as 500", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		WriteError(rec, httptest.NewRequest(http.MethodGet, "/", nil), New("boom"))
+
+		if rec.Code != http.StatusInternalServerError {
+			t.Fatalf("\n got:  %v\n want: %v", rec.Code, http.StatusInternalServerError)
+		}
+
+		if !strings.Contains(rec.Body.String(), `"message":"boom"`) {
+			t.Fatalf("unexpected body: %s", rec.Body.String())
+		}
+	})
+}